@@ -0,0 +1,82 @@
+package main
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/script"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply SCRIPT FILE",
+	Short: "Replay a recorded edit script on a file.",
+	Long:  `Replays the steps of a script recorded in the app with :record (deletes, edits, jq transforms and find/replaces, each on the rows matching its where filter or on hand-picked rows identified by content hash) on FILE. The result is written to --output or as JSONL to stdout, with a report of every step on stderr. --dry-run only prints the report.`,
+	Args:  cobra.ExactArgs(2),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		var dryRun, _ = cmd.Flags().GetBool("dry-run")
+		var outputPath, _ = cmd.Flags().GetString("output")
+		scriptPath, inputPath := args[0], args[1]
+		requireInputFile(scriptPath)
+		requireInputFile(inputPath)
+		requireDistinctOutput(outputPath, inputPath, scriptPath)
+
+		steps, err := script.Load(scriptPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		entries, header, err := editor.Load(inputPath, loadOptions(cmd))
+		if err != nil {
+			fmt.Printf("Error: Cannot read '%s': %v\n", inputPath, err)
+			os.Exit(1)
+		}
+
+		result, report, err := script.Apply(steps, entries)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if dryRun {
+			printApplyReport(os.Stdout, report, true)
+			return
+		}
+
+		if outputPath == "" {
+			if err := editor.WriteJSONLTo(os.Stdout, result); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			printApplyReport(os.Stderr, report, false)
+			return
+		}
+		if err := editor.Write(outputPath, result, header); err != nil {
+			fmt.Printf("Error: Cannot write '%s': %v\n", outputPath, err)
+			os.Exit(1)
+		}
+		printApplyReport(os.Stdout, report, false)
+	},
+}
+
+func printApplyReport(w io.Writer, report script.Report, dryRun bool) {
+	for i, step := range report.Steps {
+		outcome := "changed"
+		if step.Step.Op == script.OpDelete {
+			outcome = "deleted"
+		}
+		fmt.Fprintf(w, "step %d: %s — %d rows matched, %d %s\n", i+1, step.Step.Describe(), step.Matched, step.Changed, outcome)
+	}
+	if dryRun {
+		fmt.Fprintf(w, "Dry run: %d rows would become %d, nothing written.\n", report.RowsBefore, report.RowsAfter)
+		return
+	}
+	fmt.Fprintf(w, "%d rows became %d.\n", report.RowsBefore, report.RowsAfter)
+}
+
+func init() {
+	applyCmd.Flags().Bool("dry-run", false, "only report what every step would change")
+	applyCmd.Flags().StringP("output", "o", "", "file to write instead of stdout")
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"cutl/internal/dataset"
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"cutl/internal/tui"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff OLD NEW",
+	Short: "Show added, removed and modified rows between two versions of a file.",
+	Long:  `Matches the rows of OLD and NEW by a jq --key such as .id and lists added, removed and modified rows with a per-field diff. --format jsonl prints one change object per line for scripts, --exit-code exits with 1 when there are differences. --interactive opens the changes in the app, where they can be accepted or rejected; W writes OLD with the accepted changes to --output (default OLD.result.jsonl).`,
+	Args:  cobra.ExactArgs(2),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		var key, _ = cmd.Flags().GetString("key")
+		var format, _ = cmd.Flags().GetString("format")
+		var exitCode, _ = cmd.Flags().GetBool("exit-code")
+		var interactive, _ = cmd.Flags().GetBool("interactive")
+		var outputPath, _ = cmd.Flags().GetString("output")
+		oldPath, newPath := args[0], args[1]
+		requireInputFile(oldPath)
+		requireInputFile(newPath)
+
+		if interactive {
+			var debug, _ = cmd.Flags().GetBool("debug")
+			if loggerFile := initDebugLog(debug); loggerFile != nil {
+				defer loggerFile.Close()
+			}
+			if outputPath == "" {
+				outputPath = dataset.SplitPath(oldPath, "result")
+			}
+			var schemaPath, _ = cmd.Flags().GetString("schema")
+			ui := tui.New(newPath, schemaPath)
+			ui.SetLoadOptions(loadOptions(cmd))
+			ui.SetDiffInputs(oldPath, key, outputPath)
+			runProgram(ui)
+			return
+		}
+
+		oldEntries, _, err := editor.Load(oldPath, loadOptions(cmd))
+		if err != nil {
+			fmt.Printf("Error: Cannot read '%s': %v\n", oldPath, err)
+			os.Exit(1)
+		}
+		newEntries, _, err := editor.Load(newPath, loadOptions(cmd))
+		if err != nil {
+			fmt.Printf("Error: Cannot read '%s': %v\n", newPath, err)
+			os.Exit(1)
+		}
+		result, err := dataset.Diff(oldEntries, newEntries, key)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		switch format {
+		case "text":
+			printDiff(os.Stdout, result)
+		case "jsonl":
+			if err := writeDiffJSONL(os.Stdout, result); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		default:
+			fmt.Printf("Error: Unknown format %q (use text or jsonl)\n", format)
+			os.Exit(1)
+		}
+		if exitCode && len(result.Changes) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func printDiff(w io.Writer, result dataset.DiffResult) {
+	for _, change := range result.Changes {
+		switch change.Kind {
+		case dataset.ChangeAdded:
+			fmt.Fprintf(w, "+ %s  %s\n", change.Key, compactJSON(change.New.Data))
+		case dataset.ChangeRemoved:
+			fmt.Fprintf(w, "- %s  %s\n", change.Key, compactJSON(change.Old.Data))
+		case dataset.ChangeModified:
+			fmt.Fprintf(w, "~ %s\n", change.Key)
+			for _, field := range change.Fields {
+				fmt.Fprintf(w, "    %s: %s → %s\n", field.Path, fieldValue(field.Old, field.OldMissing), fieldValue(field.New, field.NewMissing))
+			}
+		}
+	}
+	added, removed, modified := result.Counts()
+	fmt.Fprintf(w, "%d added, %d removed, %d modified, %d unchanged\n", added, removed, modified, result.Unchanged)
+}
+
+func fieldValue(value any, missing bool) string {
+	if missing {
+		return "(missing)"
+	}
+	return compactJSON(value)
+}
+
+func compactJSON(value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return query.Format(value)
+	}
+	return string(encoded)
+}
+
+// writeDiffJSONL prints one object per change, e.g.
+// {"key":"2","change":"modified","fields":[{"path":".text","old":"a","new":"b"}]}.
+func writeDiffJSONL(w io.Writer, result dataset.DiffResult) error {
+	encoder := json.NewEncoder(w)
+	for _, change := range result.Changes {
+		record := map[string]any{"key": change.Key, "change": change.Kind}
+		if change.Old != nil {
+			record["old"] = change.Old.Data
+			record["old_line"] = change.Old.Line
+		}
+		if change.New != nil {
+			record["new"] = change.New.Data
+			record["new_line"] = change.New.Line
+		}
+		if change.Kind == dataset.ChangeModified {
+			fields := make([]map[string]any, 0, len(change.Fields))
+			for _, field := range change.Fields {
+				entry := map[string]any{"path": field.Path}
+				if !field.OldMissing {
+					entry["old"] = field.Old
+				}
+				if !field.NewMissing {
+					entry["new"] = field.New
+				}
+				fields = append(fields, entry)
+			}
+			record["fields"] = fields
+		}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	diffCmd.Flags().String("key", "", "jq expression identifying a row in both files, e.g. .id")
+	diffCmd.Flags().String("format", "text", "output format: text or jsonl")
+	diffCmd.Flags().Bool("exit-code", false, "exit with 1 when the files differ")
+	diffCmd.Flags().BoolP("interactive", "i", false, "review the changes in the app and write the accepted ones")
+	diffCmd.Flags().StringP("output", "o", "", "result file written by the app (default: OLD.result.jsonl)")
+	diffCmd.MarkFlagRequired("key")
+}
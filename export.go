@@ -0,0 +1,126 @@
+package main
+
+import (
+	"cutl/internal/config"
+	"cutl/internal/editor"
+	"cutl/internal/export"
+	"cutl/internal/query"
+	"cutl/internal/tui/cutable"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the columns of a JSONL file as CSV, TSV, Markdown or a JSON array.",
+	Long:  `Renders the columns of every entry of --input (JSONL, CSV, TSV or a JSON array), by default the columns saved for the file in the cutl config, into CSV or TSV with proper quoting, a Markdown table or a JSON array. The format follows --format or the extension of --output; without --output the result goes to stdout.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		var inputPath, _ = cmd.Flags().GetString("input")
+		var outputPath, _ = cmd.Flags().GetString("output")
+		var formatName, _ = cmd.Flags().GetString("format")
+		var columnList, _ = cmd.Flags().GetString("columns")
+		var headers, _ = cmd.Flags().GetStringSlice("headers")
+		var filter, _ = cmd.Flags().GetString("filter")
+		requireInputFile(inputPath)
+		requireDistinctOutput(outputPath, inputPath)
+
+		format := export.CSV
+		if formatName != "" {
+			parsed, err := export.ParseFormat(formatName)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			format = parsed
+		} else if guessed, ok := export.FormatForPath(outputPath); ok {
+			format = guessed
+		}
+
+		entries, _, err := editor.Load(inputPath, loadOptions(cmd))
+		if err != nil {
+			fmt.Printf("Error: Cannot read '%s': %v\n", inputPath, err)
+			os.Exit(1)
+		}
+
+		if entries, err = filterEntries(entries, filter); err != nil {
+			fmt.Printf("Error: Invalid filter: %v\n", err)
+			os.Exit(1)
+		}
+
+		var columns []string
+		if columnList != "" {
+			for _, column := range cutable.SplitTopLevel(columnList, ",") {
+				columns = append(columns, strings.TrimSpace(column))
+			}
+		}
+		if len(columns) == 0 {
+			if cfg, err := config.Load(); err == nil {
+				if fileConfig, exists := cfg.GetFileConfig(inputPath); exists {
+					columns = fileConfig.Columns
+				}
+			}
+		}
+		if len(columns) == 0 && len(entries) > 0 {
+			if first, ok := entries[0].Data.(map[string]interface{}); ok {
+				columns = cutable.DiscoverColumnQueries(first)
+			}
+		}
+		if len(headers) == 0 {
+			headers = nil
+		}
+
+		table, err := export.Columns(entries, columns, headers)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if outputPath == "" {
+			if err := table.Write(os.Stdout, format); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if err := table.WriteFile(outputPath, format); err != nil {
+			fmt.Printf("Error: Cannot write '%s': %v\n", outputPath, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Exported %d entries to %s.\n", len(table.Rows), outputPath)
+	},
+}
+
+// filterEntries keeps the entries a jq filter selects; an empty filter keeps
+// all of them.
+func filterEntries(entries []editor.Entry, filter string) ([]editor.Entry, error) {
+	if filter == "" {
+		return entries, nil
+	}
+	q, err := query.Compile(filter)
+	if err != nil {
+		return nil, err
+	}
+	filtered := entries[:0]
+	for _, entry := range entries {
+		if q.Truthy(entry.Data) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered, nil
+}
+
+func init() {
+	names := make([]string, 0, len(export.Formats))
+	for _, format := range export.Formats {
+		names = append(names, string(format))
+	}
+	exportCmd.Flags().String("format", "", "output format: "+strings.Join(names, ", ")+" (default: from --output extension, else csv)")
+	exportCmd.Flags().StringP("output", "o", "", "file to write instead of stdout")
+	exportCmd.Flags().String("columns", "", "comma-separated jq column queries (default: the columns saved for the file)")
+	exportCmd.Flags().StringSlice("headers", nil, "header names, one per column (default: derived from the queries)")
+	exportCmd.Flags().String("filter", "", "jq filter selecting the exported entries")
+}
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	OpDelete    = "delete"
+	OpEdit      = "edit"
+	OpTransform = "transform"
+	OpReplace   = "replace"
+)
+
+// Record is one applied operation, a line in the log. Paths are the fields
+// an edit or replace set and Expr the jq expression of a transform or the
+// description of a find/replace; Rows lists every
+// affected row with its line in the file before the write.
+type Record struct {
+	Time  time.Time `json:"time"`
+	User  string    `json:"user"`
+	File  string    `json:"file"`
+	Op    string    `json:"op"`
+	Paths []string  `json:"paths,omitempty"`
+	Expr  string    `json:"expr,omitempty"`
+	Rows  []Row     `json:"rows"`
+}
+
+// Row identifies an affected row by the configured key, when there is one,
+// and by the hash of its content before the operation. Deleted rows carry
+// their whole content in Old; edited rows list the changed fields.
+type Row struct {
+	ID      any     `json:"id,omitempty"`
+	Line    int     `json:"line"`
+	Hash    string  `json:"hash"`
+	Old     any     `json:"old,omitempty"`
+	Changes []Field `json:"changes,omitempty"`
+}
+
+// Field is one changed value, addressed by a jq path. Added fields have a
+// null old value and removed ones a null new value.
+type Field struct {
+	Path string `json:"path"`
+	Old  any    `json:"old"`
+	New  any    `json:"new"`
+}
+
+// LogPath places the log next to the input, data.jsonl becoming
+// data.cutl-log.jsonl.
+func LogPath(jsonlPath string) string {
+	return strings.TrimSuffix(jsonlPath, filepath.Ext(jsonlPath)) + ".cutl-log.jsonl"
+}
+
+// CurrentUser names the user operations are attributed to.
+func CurrentUser() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
+
+// Append adds the records to the end of the log, creating it if needed.
+func Append(path string, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Load reads all records of a log; a missing file is an empty log.
+func Load(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
@@ -7,7 +7,79 @@ import (
 )
 
 type FileConfig struct {
-	Columns []string `json:"columns"`
+	Columns    []string        `json:"columns"`
+	Highlights []HighlightRule `json:"highlights,omitempty"`
+	Schema     string          `json:"schema,omitempty"`
+	Spans      *SpanConfig     `json:"spans,omitempty"`
+	Classify   *ClassifyConfig `json:"classify,omitempty"`
+	Review     *ReviewConfig   `json:"review,omitempty"`
+	Session    *Session        `json:"session,omitempty"`
+	Tags       []Tag           `json:"tags,omitempty"`
+	Audit      *AuditConfig    `json:"audit,omitempty"`
+}
+
+// Tag is a user-defined row tag. Its position in FileConfig.Tags decides the
+// alt+1..alt+9 hotkey that toggles it.
+type Tag struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// SpanConfig holds the paths of the text and its entity spans for
+// spaCy-style files. Empty paths fall back to .text and .spans/.entities.
+// Labels is the label set offered when annotating.
+type SpanConfig struct {
+	Text   string   `json:"text,omitempty"`
+	Spans  string   `json:"spans,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+// HighlightRule styles a row, or a single cell when Column is set, whenever
+// the jq Condition evaluates to a truthy value for that row.
+type HighlightRule struct {
+	Condition  string `json:"condition"`
+	Column     string `json:"column,omitempty"`
+	Foreground string `json:"foreground,omitempty"`
+	Background string `json:"background,omitempty"`
+	Bold       bool   `json:"bold,omitempty"`
+	Faint      bool   `json:"faint,omitempty"`
+}
+
+// ClassifyConfig drives the labeling loop: keys 1-9 write Labels[0..8] to
+// Path while the value at Text is shown. An empty Text means .text.
+type ClassifyConfig struct {
+	Path   string   `json:"path"`
+	Text   string   `json:"text,omitempty"`
+	Labels []string `json:"labels"`
+}
+
+// ReviewConfig selects where review statuses are kept: inside each row at
+// Field, or in a sidecar file next to the data when Field is empty.
+type ReviewConfig struct {
+	Field string `json:"field,omitempty"`
+}
+
+// AuditConfig turns on the change log next to the data. Key is the jq
+// expression identifying rows in the log; without it rows are identified by
+// line and content hash only.
+type AuditConfig struct {
+	Key string `json:"key,omitempty"`
+}
+
+// Session is the view state restored when a file is reopened. Marks, tag
+// assignments and the selected row are stored as row keys of the file on
+// disk (see dataset.RowKeys) so they survive external edits and stick to one
+// of several identical rows; SelectedLine is the fallback when the selected
+// row itself changed.
+type Session struct {
+	Filter        string              `json:"filter,omitempty"`
+	SortColumn    int                 `json:"sortColumn"`
+	SortAscending bool                `json:"sortAscending"`
+	Marks         []string            `json:"marks,omitempty"`
+	Selected      string              `json:"selected,omitempty"`
+	SelectedLine  int                 `json:"selectedLine,omitempty"`
+	Detail        bool                `json:"detail,omitempty"`
+	Tags          map[string][]string `json:"tags,omitempty"`
 }
 
 type Config struct {
@@ -88,10 +160,73 @@ func (c *Config) SetFileConfig(filePath string, fileConfig FileConfig) {
 }
 
 func (c *Config) UpdateColumns(filePath string, columns []string) error {
-	fileConfig := FileConfig{
-		Columns: columns,
-	}
-	
+	fileConfig, _ := c.GetFileConfig(filePath)
+	fileConfig.Columns = columns
+
+	c.SetFileConfig(filePath, fileConfig)
+	return c.Save()
+}
+
+func (c *Config) UpdateSchema(filePath string, schemaPath string) error {
+	fileConfig, _ := c.GetFileConfig(filePath)
+	fileConfig.Schema = schemaPath
+
+	c.SetFileConfig(filePath, fileConfig)
+	return c.Save()
+}
+
+func (c *Config) UpdateHighlights(filePath string, rules []HighlightRule) error {
+	fileConfig, _ := c.GetFileConfig(filePath)
+	fileConfig.Highlights = rules
+
+	c.SetFileConfig(filePath, fileConfig)
+	return c.Save()
+}
+
+func (c *Config) UpdateSpans(filePath string, spans *SpanConfig) error {
+	fileConfig, _ := c.GetFileConfig(filePath)
+	fileConfig.Spans = spans
+
+	c.SetFileConfig(filePath, fileConfig)
+	return c.Save()
+}
+
+func (c *Config) UpdateClassify(filePath string, classify *ClassifyConfig) error {
+	fileConfig, _ := c.GetFileConfig(filePath)
+	fileConfig.Classify = classify
+
 	c.SetFileConfig(filePath, fileConfig)
 	return c.Save()
-}
\ No newline at end of file
+}
+
+func (c *Config) UpdateReview(filePath string, review *ReviewConfig) error {
+	fileConfig, _ := c.GetFileConfig(filePath)
+	fileConfig.Review = review
+
+	c.SetFileConfig(filePath, fileConfig)
+	return c.Save()
+}
+
+func (c *Config) UpdateSession(filePath string, session *Session) error {
+	fileConfig, _ := c.GetFileConfig(filePath)
+	fileConfig.Session = session
+
+	c.SetFileConfig(filePath, fileConfig)
+	return c.Save()
+}
+
+func (c *Config) UpdateTags(filePath string, tags []Tag) error {
+	fileConfig, _ := c.GetFileConfig(filePath)
+	fileConfig.Tags = tags
+
+	c.SetFileConfig(filePath, fileConfig)
+	return c.Save()
+}
+
+func (c *Config) UpdateAudit(filePath string, audit *AuditConfig) error {
+	fileConfig, _ := c.GetFileConfig(filePath)
+	fileConfig.Audit = audit
+
+	c.SetFileConfig(filePath, fileConfig)
+	return c.Save()
+}
@@ -0,0 +1,249 @@
+package dataset
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// DiffField is the key under which the app records the change a row shows,
+// e.g. {"_diff": {"change": "modified", "status": "pending"}}.
+const DiffField = "_diff"
+
+// ChangeKind is the kind of difference between two versions of a dataset.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// FieldChange is one differing value, addressed by a jq path such as
+// `.meta.source` or `.spans[2].label`. A missing side means the field was
+// added or removed.
+type FieldChange struct {
+	Path       string
+	Old        any
+	New        any
+	OldMissing bool
+	NewMissing bool
+}
+
+// Change describes one row that differs. Old is nil for added rows, New is
+// nil for removed ones; Fields is only set for modified rows.
+type Change struct {
+	Key    string
+	Kind   ChangeKind
+	Old    *editor.Entry
+	New    *editor.Entry
+	Fields []FieldChange
+}
+
+// DiffResult lists the changes in the order of the new file, followed by the
+// removed rows in the order of the old one.
+type DiffResult struct {
+	Changes   []Change
+	Unchanged int
+}
+
+// Counts returns the number of added, removed and modified rows.
+func (r DiffResult) Counts() (added, removed, modified int) {
+	for _, change := range r.Changes {
+		switch change.Kind {
+		case ChangeAdded:
+			added++
+		case ChangeRemoved:
+			removed++
+		case ChangeModified:
+			modified++
+		}
+	}
+	return added, removed, modified
+}
+
+// Diff matches the rows of both versions by the jq key expression and
+// compares them field by field. Rows sharing a key are paired in order; rows
+// whose key is missing or null are matched by their whole content.
+func Diff(oldEntries, newEntries []editor.Entry, key string) (DiffResult, error) {
+	if key == "" {
+		return DiffResult{}, fmt.Errorf("a diff needs a key expression, e.g. .id")
+	}
+	keyQuery, err := query.Compile(key)
+	if err != nil {
+		return DiffResult{}, fmt.Errorf("key: %w", err)
+	}
+
+	diffKey := func(data any) string {
+		if k := groupKey(keyQuery, data); k != "" {
+			return k
+		}
+		return "#" + RowHash(data)
+	}
+
+	pending := make(map[string][]int)
+	for i, entry := range oldEntries {
+		k := diffKey(entry.Data)
+		pending[k] = append(pending[k], i)
+	}
+
+	var result DiffResult
+	matched := make([]bool, len(oldEntries))
+	for i := range newEntries {
+		newEntry := &newEntries[i]
+		k := diffKey(newEntry.Data)
+		candidates := pending[k]
+		if len(candidates) == 0 {
+			result.Changes = append(result.Changes, Change{Key: displayKey(k), Kind: ChangeAdded, New: newEntry})
+			continue
+		}
+		pending[k] = candidates[1:]
+		matched[candidates[0]] = true
+		oldEntry := &oldEntries[candidates[0]]
+
+		fields := DiffFields(oldEntry.Data, newEntry.Data)
+		if len(fields) == 0 {
+			result.Unchanged++
+			continue
+		}
+		result.Changes = append(result.Changes, Change{Key: displayKey(k), Kind: ChangeModified, Old: oldEntry, New: newEntry, Fields: fields})
+	}
+	for i := range oldEntries {
+		if !matched[i] {
+			result.Changes = append(result.Changes, Change{Key: displayKey(diffKey(oldEntries[i].Data)), Kind: ChangeRemoved, Old: &oldEntries[i]})
+		}
+	}
+	return result, nil
+}
+
+// ApplyDiff builds the result of a review: the old entries with the accepted
+// changes applied. Accepted modifications replace their old row in place,
+// accepted removals drop it and accepted additions are appended in order.
+func ApplyDiff(oldEntries []editor.Entry, changes []Change, accepted func(int) bool) []editor.Entry {
+	byOldLine := make(map[int]int)
+	var added []editor.Entry
+	for i, change := range changes {
+		if !accepted(i) {
+			continue
+		}
+		switch change.Kind {
+		case ChangeAdded:
+			if change.New != nil {
+				added = append(added, *change.New)
+			}
+		case ChangeModified, ChangeRemoved:
+			if change.Old != nil {
+				byOldLine[change.Old.Line] = i
+			}
+		}
+	}
+
+	result := make([]editor.Entry, 0, len(oldEntries)+len(added))
+	for _, entry := range oldEntries {
+		if i, ok := byOldLine[entry.Line]; ok {
+			if changes[i].Kind == ChangeRemoved {
+				continue
+			}
+			if changes[i].New != nil {
+				entry.Data = changes[i].New.Data
+			}
+		}
+		result = append(result, entry)
+	}
+	result = append(result, added...)
+	for i := range result {
+		result[i].Line = i + 1
+	}
+	return result
+}
+
+// displayKey hides the content hash used for rows without a key.
+func displayKey(key string) string {
+	if len(key) > 0 && key[0] == '#' {
+		return ""
+	}
+	return key
+}
+
+// DiffFields compares two values recursively. Objects are compared key by
+// key, arrays of equal length element by element; anything else is reported
+// as a whole.
+func DiffFields(oldValue, newValue any) []FieldChange {
+	var changes []FieldChange
+	diffValues("", oldValue, newValue, &changes)
+	return changes
+}
+
+func diffValues(path string, oldValue, newValue any, changes *[]FieldChange) {
+	switch oldTyped := oldValue.(type) {
+	case map[string]any:
+		if newTyped, ok := newValue.(map[string]any); ok {
+			keys := make([]string, 0, len(oldTyped)+len(newTyped))
+			for k := range oldTyped {
+				keys = append(keys, k)
+			}
+			for k := range newTyped {
+				if _, ok := oldTyped[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				oldField, inOld := oldTyped[k]
+				newField, inNew := newTyped[k]
+				fieldPath := appendPath(path, k)
+				switch {
+				case !inOld:
+					*changes = append(*changes, FieldChange{Path: fieldPath, New: newField, OldMissing: true})
+				case !inNew:
+					*changes = append(*changes, FieldChange{Path: fieldPath, Old: oldField, NewMissing: true})
+				default:
+					diffValues(fieldPath, oldField, newField, changes)
+				}
+			}
+			return
+		}
+	case []any:
+		if newTyped, ok := newValue.([]any); ok && len(newTyped) == len(oldTyped) {
+			base := path
+			if base == "" {
+				base = "."
+			}
+			for i := range oldTyped {
+				diffValues(fmt.Sprintf("%s[%d]", base, i), oldTyped[i], newTyped[i], changes)
+			}
+			return
+		}
+	}
+
+	if !sameJSON(oldValue, newValue) {
+		if path == "" {
+			path = "."
+		}
+		*changes = append(*changes, FieldChange{Path: path, Old: oldValue, New: newValue})
+	}
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// appendPath extends a jq path by an object key, quoting keys that are not
+// identifiers.
+func appendPath(path, key string) string {
+	if identifierPattern.MatchString(key) {
+		return path + "." + key
+	}
+	encoded, _ := json.Marshal(key)
+	if path == "" {
+		path = "."
+	}
+	return path + "[" + string(encoded) + "]"
+}
+
+func sameJSON(a, b any) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
@@ -0,0 +1,296 @@
+package dataset
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	shingleSize   = 5
+	minhashBands  = 16
+	minhashRows   = 4
+	minhashLength = minhashBands * minhashRows
+)
+
+// DuplicateOptions controls how FindDuplicates compares entries. An empty
+// KeyExpr compares whole rows. Normalize lowercases the key and strips
+// punctuation and repeated whitespace. A Similarity between 0 and 1 also
+// clusters keys whose character shingles have at least that Jaccard overlap.
+type DuplicateOptions struct {
+	KeyExpr    string
+	Normalize  bool
+	Similarity float64
+}
+
+// Cluster is a group of entries considered duplicates of each other. Lines
+// are sorted, so Lines[0] is the entry that is kept when deduplicating.
+type Cluster struct {
+	Label string
+	Lines []int
+}
+
+type keyedEntry struct {
+	line int
+	key  string
+}
+
+// FindDuplicates returns all clusters with more than one entry, largest first.
+// Entries whose key is missing, null or empty are never clustered.
+func FindDuplicates(entries []editor.Entry, opts DuplicateOptions) ([]Cluster, error) {
+	var keyQuery *query.Query
+	if opts.KeyExpr != "" {
+		q, err := query.Compile(opts.KeyExpr)
+		if err != nil {
+			return nil, err
+		}
+		keyQuery = q
+	}
+
+	keyed := make([]keyedEntry, 0, len(entries))
+	for _, entry := range entries {
+		value := entry.Data
+		if keyQuery != nil {
+			v, ok, err := keyQuery.First(entry.Data)
+			if err != nil || !ok {
+				continue
+			}
+			value = v
+		}
+		// Rows without a key are not duplicates of each other.
+		if value == nil {
+			continue
+		}
+		key := duplicateKey(value)
+		if opts.Normalize {
+			key = NormalizeText(key)
+		}
+		if key == "" {
+			continue
+		}
+		keyed = append(keyed, keyedEntry{line: entry.Line, key: key})
+	}
+
+	// Exact matches are grouped first; near-duplicate detection then only
+	// has to compare one representative per exact group.
+	groups := make(map[string][]int)
+	var order []string
+	for _, k := range keyed {
+		if _, ok := groups[k.key]; !ok {
+			order = append(order, k.key)
+		}
+		groups[k.key] = append(groups[k.key], k.line)
+	}
+
+	uf := newUnionFind(len(order))
+	if opts.Similarity > 0 && opts.Similarity < 1 {
+		linkSimilar(order, opts.Similarity, uf)
+	}
+
+	merged := make(map[int]*Cluster)
+	for i, key := range order {
+		root := uf.find(i)
+		cluster, ok := merged[root]
+		if !ok {
+			cluster = &Cluster{Label: order[root]}
+			merged[root] = cluster
+		}
+		cluster.Lines = append(cluster.Lines, groups[key]...)
+	}
+
+	var clusters []Cluster
+	for _, cluster := range merged {
+		if len(cluster.Lines) < 2 {
+			continue
+		}
+		sort.Ints(cluster.Lines)
+		clusters = append(clusters, *cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].Lines) != len(clusters[j].Lines) {
+			return len(clusters[i].Lines) > len(clusters[j].Lines)
+		}
+		return clusters[i].Lines[0] < clusters[j].Lines[0]
+	})
+	return clusters, nil
+}
+
+// DuplicateLines returns every line of every cluster except the first, i.e.
+// the entries to remove so one copy of each cluster survives.
+func DuplicateLines(clusters []Cluster) []int {
+	var lines []int
+	for _, cluster := range clusters {
+		lines = append(lines, cluster.Lines[1:]...)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+func duplicateKey(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	// encoding/json sorts object keys, which makes this a canonical form.
+	data, err := json.Marshal(value)
+	if err != nil {
+		return query.Format(value)
+	}
+	return string(data)
+}
+
+// NormalizeText lowercases s, drops punctuation and collapses whitespace.
+func NormalizeText(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r):
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// linkSimilar unions keys whose shingle sets are at least threshold similar.
+// Candidates are found with MinHash and locality-sensitive hashing so the
+// comparison does not grow quadratically with the number of keys.
+func linkSimilar(keys []string, threshold float64, uf *unionFind) {
+	shingles := make([]map[uint64]struct{}, len(keys))
+	buckets := make(map[uint64][]int)
+
+	for i, key := range keys {
+		shingles[i] = shingleSet(key)
+		signature := minhash(shingles[i])
+		for band := 0; band < minhashBands; band++ {
+			h := fnv.New64a()
+			var buf [8]byte
+			buf[0] = byte(band)
+			h.Write(buf[:1])
+			for _, v := range signature[band*minhashRows : (band+1)*minhashRows] {
+				for b := 0; b < 8; b++ {
+					buf[b] = byte(v >> (8 * b))
+				}
+				h.Write(buf[:])
+			}
+			bucket := h.Sum64()
+			buckets[bucket] = append(buckets[bucket], i)
+		}
+	}
+
+	for _, members := range buckets {
+		for a := 0; a < len(members); a++ {
+			for b := a + 1; b < len(members); b++ {
+				i, j := members[a], members[b]
+				if uf.find(i) == uf.find(j) {
+					continue
+				}
+				if jaccard(shingles[i], shingles[j]) >= threshold {
+					uf.union(i, j)
+				}
+			}
+		}
+	}
+}
+
+func shingleSet(s string) map[uint64]struct{} {
+	runes := []rune(s)
+	set := make(map[uint64]struct{})
+	if len(runes) <= shingleSize {
+		set[hashString(s)] = struct{}{}
+		return set
+	}
+	for i := 0; i+shingleSize <= len(runes); i++ {
+		set[hashString(string(runes[i:i+shingleSize]))] = struct{}{}
+	}
+	return set
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func minhash(set map[uint64]struct{}) [minhashLength]uint64 {
+	var signature [minhashLength]uint64
+	for i := range signature {
+		signature[i] = ^uint64(0)
+	}
+	for v := range set {
+		for i := range signature {
+			if h := mix(v ^ uint64(i+1)*0x9E3779B97F4A7C15); h < signature[i] {
+				signature[i] = h
+			}
+		}
+	}
+	return signature
+}
+
+// mix is the splitmix64 finalizer, used to derive independent hash functions.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	x ^= x >> 31
+	return x
+}
+
+func jaccard(a, b map[uint64]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	intersection := 0
+	for v := range a {
+		if _, ok := b[v]; ok {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+type unionFind struct {
+	parent []int
+}
+
+func newUnionFind(n int) *unionFind {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	return &unionFind{parent: parent}
+}
+
+func (u *unionFind) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+// union keeps the smaller index as root so clusters are labelled by the
+// earliest key.
+func (u *unionFind) union(i, j int) {
+	ri, rj := u.find(i), u.find(j)
+	if ri == rj {
+		return
+	}
+	if rj < ri {
+		ri, rj = rj, ri
+	}
+	u.parent[rj] = ri
+}
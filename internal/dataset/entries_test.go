@@ -0,0 +1,34 @@
+package dataset
+
+import (
+	"cutl/internal/editor"
+	"encoding/json"
+	"testing"
+)
+
+// decodeEntries reads one JSON row per argument, numbered from 1.
+func decodeEntries(t *testing.T, lines ...string) []editor.Entry {
+	t.Helper()
+	entries := make([]editor.Entry, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &entries[i].Data); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		entries[i].Line = i + 1
+	}
+	return entries
+}
+
+// encodeEntries writes the rows back as compact JSON, keys sorted.
+func encodeEntries(t *testing.T, entries []editor.Entry) []string {
+	t.Helper()
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		encoded, err := json.Marshal(entry.Data)
+		if err != nil {
+			t.Fatalf("encode line %d: %v", entry.Line, err)
+		}
+		lines[i] = string(encoded)
+	}
+	return lines
+}
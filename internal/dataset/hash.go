@@ -0,0 +1,44 @@
+package dataset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// RowHash identifies a row by its content, so state attached to a row
+// survives reordering and edits to other rows. encoding/json sorts object
+// keys, so the hash does not depend on key order in the file.
+func RowHash(data any) string {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		encoded = []byte(fmt.Sprintf("%v", data))
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:16])
+}
+
+// RowKeys identifies each row by its content and, for identical rows, by
+// its occurrence: the first copy is keyed by its RowHash alone, later ones
+// by the hash and their index, e.g. "<hash>#2" for the third copy. State
+// saved by key therefore sticks to one copy instead of every duplicate, and
+// keys saved as plain hashes still find the first copy.
+func RowKeys(rows []any) []string {
+	keys := make([]string, len(rows))
+	seen := make(map[string]int, len(rows))
+	for i, data := range rows {
+		hash := RowHash(data)
+		keys[i] = RowKey(hash, seen[hash])
+		seen[hash]++
+	}
+	return keys
+}
+
+// RowKey is the key of the given copy of the rows with that hash.
+func RowKey(hash string, occurrence int) string {
+	if occurrence == 0 {
+		return hash
+	}
+	return fmt.Sprintf("%s#%d", hash, occurrence)
+}
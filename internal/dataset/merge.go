@@ -0,0 +1,264 @@
+package dataset
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SourceField is the key under which merged entries record the file and
+// line they came from, e.g. {"_source": {"file": "b.jsonl", "line": 12}}.
+const SourceField = "_source"
+
+// MergeInput is one file taking part in a merge.
+type MergeInput struct {
+	Path    string
+	Entries []editor.Entry
+}
+
+// ConflictPolicy decides which entry survives when several share a key.
+type ConflictPolicy string
+
+const (
+	ConflictFirst       ConflictPolicy = "first"
+	ConflictLast        ConflictPolicy = "last"
+	ConflictInteractive ConflictPolicy = "interactive"
+)
+
+func ParseConflictPolicy(value string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case ConflictFirst, ConflictLast, ConflictInteractive:
+		return policy, nil
+	case "":
+		return ConflictFirst, nil
+	}
+	return "", fmt.Errorf("unknown conflict policy %q (use first, last or interactive)", value)
+}
+
+// MergeOptions controls Merge. Key is a jq expression such as `.id`; without
+// it the inputs are only concatenated. Provenance adds SourceField to every
+// object entry; inputs that already have that field are refused then.
+type MergeOptions struct {
+	Key        string
+	Policy     ConflictPolicy
+	Provenance bool
+}
+
+// Source is the file and line an entry was read from.
+type Source struct {
+	File string
+	Line int
+}
+
+// Conflict lists the differing versions of one key, in input order, and
+// where each of them came from.
+type Conflict struct {
+	Key      string
+	Versions []editor.Entry
+	Sources  []Source
+}
+
+// MergeResult holds the merged entries, numbered from 1, and the source of
+// each of them. Dropped counts the entries removed by deduplication.
+// Conflicts are only left unresolved with ConflictInteractive; all their
+// versions are then part of Entries.
+type MergeResult struct {
+	Entries   []editor.Entry
+	Sources   []Source
+	Conflicts []Conflict
+	Dropped   int
+}
+
+// Merge concatenates the inputs in order. With a key, entries sharing it are
+// deduplicated: exact copies always collapse into the first one, differing
+// versions are resolved by the policy. Entries whose key is missing or null
+// are never deduplicated.
+func Merge(inputs []MergeInput, opts MergeOptions) (MergeResult, error) {
+	var keyQuery *query.Query
+	if opts.Key != "" {
+		q, err := query.Compile(opts.Key)
+		if err != nil {
+			return MergeResult{}, fmt.Errorf("key: %w", err)
+		}
+		keyQuery = q
+	}
+
+	var all []editor.Entry
+	var sources []Source
+	for _, input := range inputs {
+		for _, entry := range input.Entries {
+			if object, ok := entry.Data.(map[string]any); ok && opts.Provenance {
+				if _, taken := object[SourceField]; taken {
+					return MergeResult{}, fmt.Errorf("%s line %d already has a %s field", input.Path, entry.Line, SourceField)
+				}
+			}
+			all = append(all, entry)
+			sources = append(sources, Source{File: input.Path, Line: entry.Line})
+		}
+	}
+
+	// versions holds the indexes of the distinct versions of every key.
+	versions := make(map[string][]int)
+	var keys []string
+	keep := make([]bool, len(all))
+	for i, entry := range all {
+		key := groupKey(keyQuery, entry.Data)
+		if key == "" {
+			keep[i] = true
+			continue
+		}
+		duplicate := false
+		for v, j := range versions[key] {
+			if sameContent(all[i].Data, all[j].Data) {
+				// With last wins, a repeated version counts as seen last.
+				if opts.Policy == ConflictLast {
+					versions[key][v] = i
+				}
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		if _, ok := versions[key]; !ok {
+			keys = append(keys, key)
+		}
+		versions[key] = append(versions[key], i)
+	}
+
+	var conflicting []string
+	for _, key := range keys {
+		indexes := versions[key]
+		switch {
+		case len(indexes) == 1 || opts.Policy == ConflictInteractive:
+			for _, i := range indexes {
+				keep[i] = true
+			}
+			if len(indexes) > 1 {
+				conflicting = append(conflicting, key)
+			}
+		case opts.Policy == ConflictLast:
+			last := indexes[0]
+			for _, i := range indexes {
+				if i > last {
+					last = i
+				}
+			}
+			keep[last] = true
+		default:
+			keep[indexes[0]] = true
+		}
+	}
+
+	result := MergeResult{}
+	merged := make(map[int]int, len(all))
+	for i, entry := range all {
+		if !keep[i] {
+			result.Dropped++
+			continue
+		}
+		entry.Line = len(result.Entries) + 1
+		if opts.Provenance {
+			entry.Data = withSource(entry.Data, sources[i])
+		}
+		merged[i] = entry.Line
+		result.Entries = append(result.Entries, entry)
+		result.Sources = append(result.Sources, sources[i])
+	}
+	for _, key := range conflicting {
+		conflict := Conflict{Key: key}
+		for _, i := range versions[key] {
+			conflict.Versions = append(conflict.Versions, result.Entries[merged[i]-1])
+			conflict.Sources = append(conflict.Sources, sources[i])
+		}
+		result.Conflicts = append(result.Conflicts, conflict)
+	}
+	return result, nil
+}
+
+func withSource(data any, source Source) any {
+	object, ok := data.(map[string]any)
+	if !ok {
+		return data
+	}
+	copied := make(map[string]any, len(object)+1)
+	for key, value := range object {
+		copied[key] = value
+	}
+	copied[SourceField] = map[string]any{"file": source.File, "line": float64(source.Line)}
+	return copied
+}
+
+// WithoutFields returns the entries without the given top-level fields,
+// leaving the given slice untouched.
+func WithoutFields(entries []editor.Entry, fields ...string) []editor.Entry {
+	stripped := make([]editor.Entry, len(entries))
+	for i, entry := range entries {
+		stripped[i] = entry
+		object, ok := entry.Data.(map[string]any)
+		if !ok {
+			continue
+		}
+		present := false
+		for _, field := range fields {
+			if _, ok := object[field]; ok {
+				present = true
+			}
+		}
+		if !present {
+			continue
+		}
+		copied := make(map[string]any, len(object))
+		for key, value := range object {
+			copied[key] = value
+		}
+		for _, field := range fields {
+			delete(copied, field)
+		}
+		stripped[i].Data = copied
+	}
+	return stripped
+}
+
+// SourceOf reads the file and line recorded by a merge.
+func SourceOf(data any) (string, int, bool) {
+	object, ok := data.(map[string]any)
+	if !ok {
+		return "", 0, false
+	}
+	source, ok := object[SourceField].(map[string]any)
+	if !ok {
+		return "", 0, false
+	}
+	file, _ := source["file"].(string)
+	line, _ := source["line"].(float64)
+	return file, int(line), file != ""
+}
+
+// sameContent compares two entries as read, before any provenance is added.
+func sameContent(a, b any) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
+
+// LoadMergeInputs reads every file with editor.Load. The header of the first
+// tabular file is returned for writing the result back as a table.
+func LoadMergeInputs(paths []string, options editor.LoadOptions) ([]MergeInput, []string, error) {
+	inputs := make([]MergeInput, 0, len(paths))
+	var header []string
+	for _, path := range paths {
+		entries, fileHeader, err := editor.Load(path, options)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if header == nil {
+			header = fileHeader
+		}
+		inputs = append(inputs, MergeInput{Path: path, Entries: entries})
+	}
+	return inputs, header, nil
+}
@@ -0,0 +1,154 @@
+package dataset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergePolicies(t *testing.T) {
+	a := MergeInput{Path: "a.jsonl", Entries: decodeEntries(t,
+		`{"id":1,"v":"a"}`,
+		`{"id":2,"v":"same"}`,
+		`{"v":"no key"}`,
+	)}
+	b := MergeInput{Path: "b.jsonl", Entries: decodeEntries(t,
+		`{"id":1,"v":"b"}`,
+		`{"id":2,"v":"same"}`,
+		`{"id":3,"v":"c"}`,
+		`{"id":null,"v":"null key"}`,
+	)}
+
+	tests := []struct {
+		name      string
+		opts      MergeOptions
+		want      []string
+		sources   []Source
+		dropped   int
+		conflicts []Conflict
+	}{
+		{
+			name: "concatenate without key",
+			opts: MergeOptions{},
+			want: []string{
+				`{"id":1,"v":"a"}`, `{"id":2,"v":"same"}`, `{"v":"no key"}`,
+				`{"id":1,"v":"b"}`, `{"id":2,"v":"same"}`, `{"id":3,"v":"c"}`, `{"id":null,"v":"null key"}`,
+			},
+		},
+		{
+			name: "first wins",
+			opts: MergeOptions{Key: ".id", Policy: ConflictFirst},
+			want: []string{
+				`{"id":1,"v":"a"}`, `{"id":2,"v":"same"}`, `{"v":"no key"}`,
+				`{"id":3,"v":"c"}`, `{"id":null,"v":"null key"}`,
+			},
+			sources: []Source{{"a.jsonl", 1}, {"a.jsonl", 2}, {"a.jsonl", 3}, {"b.jsonl", 3}, {"b.jsonl", 4}},
+			dropped: 2,
+		},
+		{
+			name: "last wins",
+			opts: MergeOptions{Key: ".id", Policy: ConflictLast},
+			want: []string{
+				`{"v":"no key"}`, `{"id":1,"v":"b"}`, `{"id":2,"v":"same"}`,
+				`{"id":3,"v":"c"}`, `{"id":null,"v":"null key"}`,
+			},
+			sources: []Source{{"a.jsonl", 3}, {"b.jsonl", 1}, {"b.jsonl", 2}, {"b.jsonl", 3}, {"b.jsonl", 4}},
+			dropped: 2,
+		},
+		{
+			name: "interactive keeps every differing version",
+			opts: MergeOptions{Key: ".id", Policy: ConflictInteractive},
+			want: []string{
+				`{"id":1,"v":"a"}`, `{"id":2,"v":"same"}`, `{"v":"no key"}`,
+				`{"id":1,"v":"b"}`, `{"id":3,"v":"c"}`, `{"id":null,"v":"null key"}`,
+			},
+			dropped: 1,
+			conflicts: []Conflict{{
+				Key:      "1",
+				Versions: decodeEntries(t, `{"id":1,"v":"a"}`, `{"id":1,"v":"b"}`),
+				Sources:  []Source{{"a.jsonl", 1}, {"b.jsonl", 1}},
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Merge([]MergeInput{a, b}, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := encodeEntries(t, result.Entries); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("entries = %v, want %v", got, test.want)
+			}
+			for i, entry := range result.Entries {
+				if entry.Line != i+1 {
+					t.Errorf("entry %d has line %d", i, entry.Line)
+				}
+			}
+			if test.sources != nil && !reflect.DeepEqual(result.Sources, test.sources) {
+				t.Errorf("sources = %v, want %v", result.Sources, test.sources)
+			}
+			if result.Dropped != test.dropped {
+				t.Errorf("dropped = %d, want %d", result.Dropped, test.dropped)
+			}
+			if len(result.Conflicts) != len(test.conflicts) {
+				t.Fatalf("conflicts = %+v, want %+v", result.Conflicts, test.conflicts)
+			}
+			for i, conflict := range result.Conflicts {
+				want := test.conflicts[i]
+				if conflict.Key != want.Key || !reflect.DeepEqual(conflict.Sources, want.Sources) ||
+					!reflect.DeepEqual(encodeEntries(t, conflict.Versions), encodeEntries(t, want.Versions)) {
+					t.Errorf("conflict %d = %+v, want %+v", i, conflict, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeProvenance(t *testing.T) {
+	inputs := []MergeInput{
+		{Path: "a.jsonl", Entries: decodeEntries(t, `{"id":1}`)},
+		{Path: "b.jsonl", Entries: decodeEntries(t, `{"id":2}`, `["not an object"]`)},
+	}
+	result, err := Merge(inputs, MergeOptions{Provenance: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`{"_source":{"file":"a.jsonl","line":1},"id":1}`,
+		`{"_source":{"file":"b.jsonl","line":1},"id":2}`,
+		`["not an object"]`,
+	}
+	if got := encodeEntries(t, result.Entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+	if inputs[0].Entries[0].Data.(map[string]any)[SourceField] != nil {
+		t.Error("the input rows were changed")
+	}
+
+	taken := []MergeInput{{Path: "c.jsonl", Entries: decodeEntries(t, `{"_source":{"index":"docs"}}`)}}
+	if _, err := Merge(taken, MergeOptions{Provenance: true}); err == nil {
+		t.Error("a row with its own _source field was accepted with provenance")
+	}
+	if _, err := Merge(taken, MergeOptions{}); err != nil {
+		t.Errorf("a row with its own _source field was refused without provenance: %v", err)
+	}
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	tests := []struct {
+		value string
+		want  ConflictPolicy
+		err   bool
+	}{
+		{value: "", want: ConflictFirst},
+		{value: "first", want: ConflictFirst},
+		{value: " Last ", want: ConflictLast},
+		{value: "interactive", want: ConflictInteractive},
+		{value: "newest", err: true},
+	}
+	for _, test := range tests {
+		got, err := ParseConflictPolicy(test.value)
+		if (err != nil) != test.err || got != test.want {
+			t.Errorf("ParseConflictPolicy(%q) = %q, %v", test.value, got, err)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package dataset
+
+import (
+	"cutl/internal/editor"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// maxPreviewLines caps the unified diff of a write preview.
+const maxPreviewLines = 5000
+
+// WritePreview summarizes what writing the current rows over the file
+// changes. Reformatted counts rows whose content is unchanged but whose
+// line is written differently, e.g. with keys in another order; Dropped
+// counts blank or unreadable lines, which are not written back. Unified
+// holds a unified diff of the lines, cut off after maxPreviewLines with
+// Truncated set. Normalized is set when the diff compares re-encoded rows
+// instead of the lines on disk, as for CSV and JSON array files.
+type WritePreview struct {
+	Deleted       int
+	Modified      int
+	Added         int
+	Reformatted   int
+	Dropped       int
+	FieldsChanged int
+	Unified       []string
+	Truncated     bool
+	Normalized    bool
+}
+
+// Changed reports whether writing would change anything.
+func (p WritePreview) Changed() bool {
+	return p.Deleted+p.Modified+p.Added+p.Reformatted+p.Dropped > 0
+}
+
+type previewOp struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// previewUnit is one line of the file on disk and the row read from it, if
+// any.
+type previewUnit struct {
+	line  int
+	text  string
+	entry *editor.Entry
+}
+
+// PreviewWrite compares the rows about to be written with the file on disk.
+// raw holds the lines of a JSONL file as they are on disk; without it the
+// rows in original are re-encoded for the comparison. origins maps the line
+// of every current row to its line in original; rows keep their relative
+// order, so a single pass aligns both sides.
+func PreviewWrite(original []editor.Entry, raw []string, current []editor.Entry, origins map[int]int, context int) WritePreview {
+	var preview WritePreview
+	var units []previewUnit
+	byLine := make(map[int]int, len(original))
+	if raw == nil {
+		preview.Normalized = true
+		for i := range original {
+			byLine[original[i].Line] = len(units)
+			units = append(units, previewUnit{line: original[i].Line, text: encodeLine(original[i].Data), entry: &original[i]})
+		}
+	} else {
+		rows := make(map[int]*editor.Entry, len(original))
+		for i := range original {
+			rows[original[i].Line] = &original[i]
+		}
+		for i, text := range raw {
+			byLine[i+1] = len(units)
+			units = append(units, previewUnit{line: i + 1, text: text, entry: rows[i+1]})
+		}
+	}
+	used := make(map[int]struct{}, len(current))
+	for _, entry := range current {
+		if origin, ok := origins[entry.Line]; ok {
+			used[origin] = struct{}{}
+		}
+	}
+
+	var ops []previewOp
+	next := 0
+	emitDeleted := func(upTo int) {
+		for ; next < len(units) && units[next].line < upTo; next++ {
+			unit := units[next]
+			if _, ok := used[unit.line]; ok && unit.entry != nil {
+				continue
+			}
+			if unit.entry == nil {
+				preview.Dropped++
+			} else {
+				preview.Deleted++
+			}
+			ops = append(ops, previewOp{'-', unit.text})
+		}
+	}
+	for _, entry := range current {
+		origin, ok := origins[entry.Line]
+		index, known := byLine[origin]
+		if !ok || !known || units[index].entry == nil {
+			preview.Added++
+			ops = append(ops, previewOp{'+', encodeLine(entry.Data)})
+			continue
+		}
+		emitDeleted(origin)
+		if next < len(units) && units[next].line == origin {
+			next++
+		}
+		old := units[index]
+		newLine := encodeLine(entry.Data)
+		if old.text == newLine {
+			ops = append(ops, previewOp{' ', old.text})
+			continue
+		}
+		if fields := len(DiffFields(old.entry.Data, entry.Data)); fields > 0 {
+			preview.Modified++
+			preview.FieldsChanged += fields
+		} else {
+			preview.Reformatted++
+		}
+		ops = append(ops, previewOp{'-', old.text}, previewOp{'+', newLine})
+	}
+	emitDeleted(math.MaxInt)
+
+	preview.Unified, preview.Truncated = unifiedHunks(ops, context)
+	return preview
+}
+
+// unifiedHunks groups the operations into hunks with context lines around
+// every change, headed by `@@ -start,count +start,count @@`.
+func unifiedHunks(ops []previewOp, context int) ([]string, bool) {
+	var lines []string
+	oldLine, newLine := make([]int, len(ops)), make([]int, len(ops))
+	o, n := 1, 1
+	for i, op := range ops {
+		oldLine[i], newLine[i] = o, n
+		if op.kind != '+' {
+			o++
+		}
+		if op.kind != '-' {
+			n++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j
+			} else if j-end > 2*context {
+				break
+			}
+		}
+		stop := end + context + 1
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+
+		oldCount, newCount := 0, 0
+		for _, op := range ops[start:stop] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		lines = append(lines, fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldLine[start], oldCount, newLine[start], newCount))
+		for _, op := range ops[start:stop] {
+			lines = append(lines, string(op.kind)+op.text)
+		}
+		if len(lines) > maxPreviewLines {
+			return lines[:maxPreviewLines], true
+		}
+		i = stop
+	}
+	return lines, false
+}
+
+func encodeLine(data any) string {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Sprintf("%v", data)
+	}
+	return string(encoded)
+}
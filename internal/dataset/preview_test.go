@@ -0,0 +1,92 @@
+package dataset
+
+import (
+	"cutl/internal/editor"
+	"reflect"
+	"testing"
+)
+
+func TestPreviewWrite(t *testing.T) {
+	original := decodeEntries(t, `{"a":1}`, `{"a":2,"b":"x"}`, `{"a":3}`)
+	raw := []string{`{"a":1}`, `{"a":2,"b":"x"}`, `{"a":3}`}
+	identity := map[int]int{1: 1, 2: 2, 3: 3}
+
+	tests := []struct {
+		name     string
+		raw      []string
+		original []editor.Entry
+		current  []editor.Entry
+		origins  map[int]int
+		want     WritePreview
+		unified  []string
+	}{
+		{
+			name:    "unchanged",
+			current: decodeEntries(t, `{"a":1}`, `{"a":2,"b":"x"}`, `{"a":3}`),
+			origins: identity,
+		},
+		{
+			name:    "modified",
+			current: decodeEntries(t, `{"a":1}`, `{"a":5,"b":"y"}`, `{"a":3}`),
+			origins: identity,
+			want:    WritePreview{Modified: 1, FieldsChanged: 2},
+			unified: []string{"@@ -1,3 +1,3 @@", ` {"a":1}`, `-{"a":2,"b":"x"}`, `+{"a":5,"b":"y"}`, ` {"a":3}`},
+		},
+		{
+			name:    "deleted and added",
+			current: decodeEntries(t, `{"a":1}`, `{"a":3}`, `{"a":4}`),
+			origins: map[int]int{1: 1, 2: 3},
+			want:    WritePreview{Deleted: 1, Added: 1},
+			unified: []string{"@@ -1,3 +1,3 @@", ` {"a":1}`, `-{"a":2,"b":"x"}`, ` {"a":3}`, `+{"a":4}`},
+		},
+		{
+			name:     "reformatted",
+			raw:      []string{`{"a":1}`, `{"b": "x", "a": 2}`, `{"a":3}`},
+			original: original,
+			current:  decodeEntries(t, `{"a":1}`, `{"a":2,"b":"x"}`, `{"a":3}`),
+			origins:  identity,
+			want:     WritePreview{Reformatted: 1},
+			unified:  []string{"@@ -1,3 +1,3 @@", ` {"a":1}`, `-{"b": "x", "a": 2}`, `+{"a":2,"b":"x"}`, ` {"a":3}`},
+		},
+		{
+			name:     "blank and unreadable lines are dropped",
+			raw:      []string{`{"a":1}`, ``, `{"a":2,"b":"x"}`, `not json`},
+			original: []editor.Entry{{Data: original[0].Data, Line: 1}, {Data: original[1].Data, Line: 3}},
+			current:  decodeEntries(t, `{"a":1}`, `{"a":2,"b":"x"}`),
+			origins:  map[int]int{1: 1, 2: 3},
+			want:     WritePreview{Dropped: 2},
+			unified:  []string{"@@ -1,4 +1,2 @@", ` {"a":1}`, `-`, ` {"a":2,"b":"x"}`, `-not json`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lines, rows := raw, original
+			if test.raw != nil {
+				lines, rows = test.raw, test.original
+			}
+			preview := PreviewWrite(rows, lines, test.current, test.origins, 3)
+			unified := preview.Unified
+			preview.Unified = nil
+			if !reflect.DeepEqual(preview, test.want) {
+				t.Errorf("preview = %+v, want %+v", preview, test.want)
+			}
+			if preview.Changed() != !reflect.DeepEqual(test.want, WritePreview{}) {
+				t.Errorf("Changed() = %v", preview.Changed())
+			}
+			if len(unified) == 0 && len(test.unified) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(unified, test.unified) {
+				t.Errorf("unified = %q, want %q", unified, test.unified)
+			}
+		})
+	}
+}
+
+func TestPreviewWriteNormalized(t *testing.T) {
+	original := decodeEntries(t, `{"b":"x","a":2}`)
+	preview := PreviewWrite(original, nil, decodeEntries(t, `{"a":2,"b":"x"}`), map[int]int{1: 1}, 3)
+	if !preview.Normalized || preview.Changed() {
+		t.Errorf("re-encoded rows: preview = %+v", preview)
+	}
+}
@@ -0,0 +1,261 @@
+package dataset
+
+import (
+	"cutl/internal/query"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ReplaceOptions describe a find/replace. In is a column or jq path such as
+// .text or .spans[].label; empty means every string value of the row. With a
+// regular expression, With may refer to captures as $1 or ${name}.
+type ReplaceOptions struct {
+	Find       string
+	With       string
+	In         string
+	Regex      bool
+	IgnoreCase bool
+	Word       bool
+}
+
+// String describes the find/replace, e.g. `"colour" → "color" in .text (word)`.
+func (o ReplaceOptions) String() string {
+	text := fmt.Sprintf("%q → %q", o.Find, o.With)
+	if o.In != "" {
+		text += " in " + o.In
+	}
+	var flags []string
+	if o.Regex {
+		flags = append(flags, "regex")
+	}
+	if o.IgnoreCase {
+		flags = append(flags, "ignore case")
+	}
+	if o.Word {
+		flags = append(flags, "word")
+	}
+	if len(flags) > 0 {
+		text += " (" + strings.Join(flags, ", ") + ")"
+	}
+	return text
+}
+
+// Replacement is one string value changed by a find/replace.
+type Replacement struct {
+	Path    string
+	Old     string
+	New     string
+	Matches int
+}
+
+// Replacer applies a compiled find/replace to rows.
+type Replacer struct {
+	options ReplaceOptions
+	pattern *regexp.Regexp
+	paths   *query.Query
+}
+
+func NewReplacer(options ReplaceOptions) (*Replacer, error) {
+	if options.Find == "" {
+		return nil, fmt.Errorf("nothing to find")
+	}
+	expr := options.Find
+	if !options.Regex {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if options.Word {
+		expr = `\b(?:` + expr + `)\b`
+	}
+	if options.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("find: %w", err)
+	}
+
+	replacer := &Replacer{options: options, pattern: pattern}
+	if options.In != "" {
+		if replacer.paths, err = query.Compile("path(" + options.In + ")"); err != nil {
+			return nil, fmt.Errorf("in: %w", err)
+		}
+	}
+	return replacer, nil
+}
+
+func (r *Replacer) Options() ReplaceOptions {
+	return r.options
+}
+
+// Replace returns a copy of data with every match replaced and the strings
+// it changed; data itself is left untouched. Values in scope that are not
+// strings are skipped.
+func (r *Replacer) Replace(data any) (any, []Replacement, error) {
+	var replacements []Replacement
+	if r.paths == nil {
+		result, _ := r.replaceAll("", data, &replacements)
+		return result, replacements, nil
+	}
+
+	paths, err := r.paths.All(data)
+	if err != nil {
+		return data, nil, err
+	}
+	result := data
+	for _, path := range paths {
+		steps, ok := path.([]any)
+		if !ok {
+			continue
+		}
+		text, ok := getPath(result, steps).(string)
+		if !ok {
+			continue
+		}
+		replaced, matches := r.replaceString(text)
+		if matches == 0 || replaced == text {
+			continue
+		}
+		result = setPath(result, steps, replaced)
+		replacements = append(replacements, Replacement{Path: formatPath(steps), Old: text, New: replaced, Matches: matches})
+	}
+	return result, replacements, nil
+}
+
+func (r *Replacer) replaceString(text string) (string, int) {
+	matches := len(r.pattern.FindAllStringIndex(text, -1))
+	if matches == 0 {
+		return text, 0
+	}
+	if r.options.Regex {
+		return r.pattern.ReplaceAllString(text, r.options.With), matches
+	}
+	return r.pattern.ReplaceAllLiteralString(text, r.options.With), matches
+}
+
+// replaceAll walks every string value; objects and arrays are only copied
+// when something below them changed.
+func (r *Replacer) replaceAll(path string, value any, replacements *[]Replacement) (any, bool) {
+	switch typed := value.(type) {
+	case string:
+		replaced, matches := r.replaceString(typed)
+		if matches == 0 || replaced == typed {
+			return value, false
+		}
+		if path == "" {
+			path = "."
+		}
+		*replacements = append(*replacements, Replacement{Path: path, Old: typed, New: replaced, Matches: matches})
+		return replaced, true
+	case map[string]any:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var copied map[string]any
+		for _, key := range keys {
+			item, changed := r.replaceAll(appendPath(path, key), typed[key], replacements)
+			if !changed {
+				continue
+			}
+			if copied == nil {
+				copied = make(map[string]any, len(typed))
+				for k, v := range typed {
+					copied[k] = v
+				}
+			}
+			copied[key] = item
+		}
+		if copied == nil {
+			return value, false
+		}
+		return copied, true
+	case []any:
+		base := path
+		if base == "" {
+			base = "."
+		}
+		var copied []any
+		for i, element := range typed {
+			item, changed := r.replaceAll(fmt.Sprintf("%s[%d]", base, i), element, replacements)
+			if !changed {
+				continue
+			}
+			if copied == nil {
+				copied = append([]any(nil), typed...)
+			}
+			copied[i] = item
+		}
+		if copied == nil {
+			return value, false
+		}
+		return copied, true
+	}
+	return value, false
+}
+
+func getPath(value any, steps []any) any {
+	for _, step := range steps {
+		switch key := step.(type) {
+		case string:
+			object, ok := value.(map[string]any)
+			if !ok {
+				return nil
+			}
+			value = object[key]
+		case int:
+			array, ok := value.([]any)
+			if !ok || key < 0 || key >= len(array) {
+				return nil
+			}
+			value = array[key]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// setPath returns a copy of value with the string at the path replaced,
+// copying only the containers along the path.
+func setPath(value any, steps []any, text string) any {
+	if len(steps) == 0 {
+		return text
+	}
+	switch key := steps[0].(type) {
+	case string:
+		object := value.(map[string]any)
+		copied := make(map[string]any, len(object))
+		for k, v := range object {
+			copied[k] = v
+		}
+		copied[key] = setPath(object[key], steps[1:], text)
+		return copied
+	case int:
+		copied := append([]any(nil), value.([]any)...)
+		copied[key] = setPath(copied[key], steps[1:], text)
+		return copied
+	}
+	return value
+}
+
+func formatPath(steps []any) string {
+	path := ""
+	for _, step := range steps {
+		switch key := step.(type) {
+		case string:
+			path = appendPath(path, key)
+		case int:
+			if path == "" {
+				path = "."
+			}
+			path += fmt.Sprintf("[%d]", key)
+		}
+	}
+	if path == "" {
+		return "."
+	}
+	return path
+}
@@ -0,0 +1,122 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestReplacer(t *testing.T) {
+	row := `{"text":"Colour and colours, colour!","title":"colour","n":3,"spans":[{"label":"colour"},{"label":"shape"}]}`
+	tests := []struct {
+		name         string
+		options      ReplaceOptions
+		want         string
+		replacements []Replacement
+	}{
+		{
+			name:    "every string",
+			options: ReplaceOptions{Find: "colour", With: "color"},
+			want:    `{"n":3,"spans":[{"label":"color"},{"label":"shape"}],"text":"Colour and colors, color!","title":"color"}`,
+			replacements: []Replacement{
+				{Path: ".spans[0].label", Old: "colour", New: "color", Matches: 1},
+				{Path: ".text", Old: "Colour and colours, colour!", New: "Colour and colors, color!", Matches: 2},
+				{Path: ".title", Old: "colour", New: "color", Matches: 1},
+			},
+		},
+		{
+			name:    "scoped to a column",
+			options: ReplaceOptions{Find: "colour", With: "color", In: ".title"},
+			want:    `{"n":3,"spans":[{"label":"colour"},{"label":"shape"}],"text":"Colour and colours, colour!","title":"color"}`,
+			replacements: []Replacement{
+				{Path: ".title", Old: "colour", New: "color", Matches: 1},
+			},
+		},
+		{
+			name:    "scoped to a jq path",
+			options: ReplaceOptions{Find: "colour", With: "color", In: ".spans[].label"},
+			want:    `{"n":3,"spans":[{"label":"color"},{"label":"shape"}],"text":"Colour and colours, colour!","title":"colour"}`,
+			replacements: []Replacement{
+				{Path: ".spans[0].label", Old: "colour", New: "color", Matches: 1},
+			},
+		},
+		{
+			name:    "ignore case and whole word",
+			options: ReplaceOptions{Find: "colour", With: "color", In: ".text", IgnoreCase: true, Word: true},
+			want:    `{"n":3,"spans":[{"label":"colour"},{"label":"shape"}],"text":"color and colours, color!","title":"colour"}`,
+			replacements: []Replacement{
+				{Path: ".text", Old: "Colour and colours, colour!", New: "color and colours, color!", Matches: 2},
+			},
+		},
+		{
+			name:    "regex captures",
+			options: ReplaceOptions{Find: `(\w+) and (\w+)`, With: "$2 and ${1}", In: ".text", Regex: true},
+			want:    `{"n":3,"spans":[{"label":"colour"},{"label":"shape"}],"text":"colours and Colour, colour!","title":"colour"}`,
+			replacements: []Replacement{
+				{Path: ".text", Old: "Colour and colours, colour!", New: "colours and Colour, colour!", Matches: 1},
+			},
+		},
+		{
+			name:    "special characters are literal without regex",
+			options: ReplaceOptions{Find: "colour!", With: "colour."},
+			want:    `{"n":3,"spans":[{"label":"colour"},{"label":"shape"}],"text":"Colour and colours, colour.","title":"colour"}`,
+			replacements: []Replacement{
+				{Path: ".text", Old: "Colour and colours, colour!", New: "Colour and colours, colour.", Matches: 1},
+			},
+		},
+		{
+			name:    "numbers are skipped",
+			options: ReplaceOptions{Find: "3", With: "4", In: ".n"},
+			want:    `{"n":3,"spans":[{"label":"colour"},{"label":"shape"}],"text":"Colour and colours, colour!","title":"colour"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := decodeEntries(t, row)[0].Data
+			replacer, err := NewReplacer(test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, replacements, err := replacer.Replace(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			encoded, _ := json.Marshal(result)
+			if string(encoded) != test.want {
+				t.Errorf("result = %s, want %s", encoded, test.want)
+			}
+			if !reflect.DeepEqual(replacements, test.replacements) {
+				t.Errorf("replacements = %+v, want %+v", replacements, test.replacements)
+			}
+			if original, _ := json.Marshal(data); string(original) != string(mustCompact(t, row)) {
+				t.Errorf("the input row was changed: %s", original)
+			}
+		})
+	}
+}
+
+func TestNewReplacerErrors(t *testing.T) {
+	for _, options := range []ReplaceOptions{
+		{Find: ""},
+		{Find: "(", Regex: true},
+		{Find: "x", In: ".a |||"},
+	} {
+		if _, err := NewReplacer(options); err == nil {
+			t.Errorf("NewReplacer(%+v) was accepted", options)
+		}
+	}
+}
+
+// mustCompact re-encodes JSON with sorted keys, as json.Marshal writes it.
+func mustCompact(t *testing.T, text string) []byte {
+	t.Helper()
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
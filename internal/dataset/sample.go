@@ -0,0 +1,67 @@
+package dataset
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// SampleOptions controls Sample. Size is the sample size, or the size per
+// group when PerGroup names a jq key such as `.label`.
+type SampleOptions struct {
+	Size     int
+	PerGroup string
+	Seed     int64
+}
+
+// Sample draws a uniform random sample with reservoir sampling, one
+// reservoir per group if PerGroup is set. The same seed yields the same
+// sample; the sampled entries keep their original order.
+func Sample(entries []editor.Entry, opts SampleOptions) ([]editor.Entry, error) {
+	if opts.Size <= 0 {
+		return nil, fmt.Errorf("sample size must be positive")
+	}
+	var groupQuery *query.Query
+	if opts.PerGroup != "" {
+		q, err := query.Compile(opts.PerGroup)
+		if err != nil {
+			return nil, fmt.Errorf("group: %w", err)
+		}
+		groupQuery = q
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	reservoirs := make(map[string][]editor.Entry)
+	seen := make(map[string]int)
+	var keys []string
+	for _, entry := range entries {
+		key := ""
+		if groupQuery != nil {
+			key = splitKey(groupQuery, entry.Data)
+		}
+		if _, ok := seen[key]; !ok {
+			keys = append(keys, key)
+		}
+		seen[key]++
+
+		reservoir := reservoirs[key]
+		if len(reservoir) < opts.Size {
+			reservoirs[key] = append(reservoir, entry)
+			continue
+		}
+		if j := rng.Intn(seen[key]); j < opts.Size {
+			reservoir[j] = entry
+		}
+	}
+
+	var sample []editor.Entry
+	for _, key := range keys {
+		sample = append(sample, reservoirs[key]...)
+	}
+	sort.Slice(sample, func(i, j int) bool {
+		return sample[i].Line < sample[j].Line
+	})
+	return sample, nil
+}
@@ -0,0 +1,67 @@
+package dataset
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	lines := make([]string, 0, 30)
+	for i := 0; i < 30; i++ {
+		label := []string{"a", "b", "c"}[i%3]
+		if i >= 27 {
+			label = "rare"
+		}
+		lines = append(lines, fmt.Sprintf(`{"i":%d,"label":%q}`, i, label))
+	}
+	entries := decodeEntries(t, lines...)
+
+	tests := []struct {
+		name   string
+		opts   SampleOptions
+		size   int
+		groups map[string]int
+	}{
+		{name: "sample", opts: SampleOptions{Size: 5, Seed: 1}, size: 5},
+		{name: "larger than the input", opts: SampleOptions{Size: 50, Seed: 1}, size: 30},
+		{
+			name:   "per group",
+			opts:   SampleOptions{Size: 4, PerGroup: ".label", Seed: 9},
+			size:   15,
+			groups: map[string]int{"a": 4, "b": 4, "c": 4, "rare": 3},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sample, err := Sample(entries, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := Sample(entries, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(sample, again) {
+				t.Error("the same seed gave a different sample")
+			}
+			if len(sample) != test.size {
+				t.Errorf("sampled %d rows, want %d", len(sample), test.size)
+			}
+			groups := make(map[string]int)
+			for i, entry := range sample {
+				if i > 0 && sample[i-1].Line >= entry.Line {
+					t.Error("the sample is not in input order")
+				}
+				groups[entry.Data.(map[string]any)["label"].(string)]++
+			}
+			if test.groups != nil && !reflect.DeepEqual(groups, test.groups) {
+				t.Errorf("groups = %v, want %v", groups, test.groups)
+			}
+		})
+	}
+
+	if _, err := Sample(entries, SampleOptions{Size: 0}); err == nil {
+		t.Error("a sample size of 0 was accepted")
+	}
+}
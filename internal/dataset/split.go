@@ -0,0 +1,202 @@
+package dataset
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SplitPart is one output of a split with its share of the entries.
+type SplitPart struct {
+	Name  string
+	Ratio float64
+}
+
+// SplitOptions controls Split. Stratify keeps the distribution of a jq
+// value (e.g. `.label`) equal across parts; Group keeps entries sharing a jq
+// key (e.g. `.doc_id`) in the same part. The same Seed yields the same split.
+type SplitOptions struct {
+	Parts    []SplitPart
+	Stratify string
+	Group    string
+	Seed     int64
+}
+
+// ParseSplitParts reads ratios written as `train=0.8,dev=0.1,test=0.1` or
+// just `80,10,10`. Ratios are normalized to sum to one; unnamed parts are
+// called train/test or train/dev/test, or part1, part2, ...
+func ParseSplitParts(spec string) ([]SplitPart, error) {
+	fields := strings.Split(spec, ",")
+	parts := make([]SplitPart, 0, len(fields))
+	total := 0.0
+	for i, field := range fields {
+		name, value, named := strings.Cut(strings.TrimSpace(field), "=")
+		if !named {
+			value = name
+			name = defaultPartName(i, len(fields))
+		}
+		name = strings.TrimSpace(name)
+		ratio, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || ratio <= 0 {
+			return nil, fmt.Errorf("invalid ratio %q (use e.g. train=0.8,dev=0.1,test=0.1)", field)
+		}
+		if name == "" || strings.ContainsAny(name, `/\`) {
+			return nil, fmt.Errorf("invalid part name in %q", field)
+		}
+		for _, part := range parts {
+			if part.Name == name {
+				return nil, fmt.Errorf("duplicate part %q", name)
+			}
+		}
+		parts = append(parts, SplitPart{Name: name, Ratio: ratio})
+		total += ratio
+	}
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("a split needs at least two parts")
+	}
+	for i := range parts {
+		parts[i].Ratio /= total
+	}
+	return parts, nil
+}
+
+func defaultPartName(index, count int) string {
+	switch count {
+	case 2:
+		return []string{"train", "test"}[index]
+	case 3:
+		return []string{"train", "dev", "test"}[index]
+	}
+	return fmt.Sprintf("part%d", index+1)
+}
+
+// splitUnit is a set of entries that must end up in the same part.
+type splitUnit struct {
+	entries []editor.Entry
+	stratum string
+}
+
+// Split partitions the entries by the part ratios. Every stratum is
+// distributed on its own: its units are shuffled and each goes to the part
+// that is furthest below its share, so group sizes are accounted for. A
+// group belongs to the stratum of its first entry; entries whose group key
+// is missing or null form groups of one. The entries of each part keep
+// their original order.
+func Split(entries []editor.Entry, opts SplitOptions) ([][]editor.Entry, error) {
+	if len(opts.Parts) < 2 {
+		return nil, fmt.Errorf("a split needs at least two parts")
+	}
+	var stratifyQuery, groupQuery *query.Query
+	var err error
+	if opts.Stratify != "" {
+		if stratifyQuery, err = query.Compile(opts.Stratify); err != nil {
+			return nil, fmt.Errorf("stratify: %w", err)
+		}
+	}
+	if opts.Group != "" {
+		if groupQuery, err = query.Compile(opts.Group); err != nil {
+			return nil, fmt.Errorf("group: %w", err)
+		}
+	}
+
+	var units []*splitUnit
+	groups := make(map[string]*splitUnit)
+	for _, entry := range entries {
+		// Entries without a group key are not grouped with each other.
+		if key := groupKey(groupQuery, entry.Data); key != "" {
+			if unit, ok := groups[key]; ok {
+				unit.entries = append(unit.entries, entry)
+				continue
+			}
+			unit := &splitUnit{entries: []editor.Entry{entry}}
+			groups[key] = unit
+			units = append(units, unit)
+		} else {
+			units = append(units, &splitUnit{entries: []editor.Entry{entry}})
+		}
+		if stratifyQuery != nil {
+			units[len(units)-1].stratum = splitKey(stratifyQuery, entry.Data)
+		}
+	}
+
+	strata := make(map[string][]*splitUnit)
+	var names []string
+	for _, unit := range units {
+		if _, ok := strata[unit.stratum]; !ok {
+			names = append(names, unit.stratum)
+		}
+		strata[unit.stratum] = append(strata[unit.stratum], unit)
+	}
+	sort.Strings(names)
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	result := make([][]editor.Entry, len(opts.Parts))
+	for _, name := range names {
+		stratum := strata[name]
+		rng.Shuffle(len(stratum), func(i, j int) {
+			stratum[i], stratum[j] = stratum[j], stratum[i]
+		})
+
+		total := 0
+		for _, unit := range stratum {
+			total += len(unit.entries)
+		}
+		sizes := make([]int, len(opts.Parts))
+		for _, unit := range stratum {
+			best := 0
+			bestDeficit := 0.0
+			for i, part := range opts.Parts {
+				deficit := part.Ratio*float64(total) - float64(sizes[i])
+				if i == 0 || deficit > bestDeficit {
+					best, bestDeficit = i, deficit
+				}
+			}
+			sizes[best] += len(unit.entries)
+			result[best] = append(result[best], unit.entries...)
+		}
+	}
+
+	for _, part := range result {
+		sort.Slice(part, func(i, j int) bool {
+			return part[i].Line < part[j].Line
+		})
+	}
+	return result, nil
+}
+
+func groupKey(q *query.Query, data any) string {
+	if q == nil {
+		return ""
+	}
+	if key := splitKey(q, data); key != "null" {
+		return key
+	}
+	return ""
+}
+
+// splitKey encodes the first value of the query, so equal values of any
+// type share a key. Entries where the query fails share the empty key.
+func splitKey(q *query.Query, data any) string {
+	value, ok, err := q.First(data)
+	if err != nil || !ok {
+		return ""
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
+
+// SplitPath names the output of a part next to the input, data.jsonl
+// becoming data.train.jsonl.
+func SplitPath(inputPath, part string) string {
+	ext := filepath.Ext(inputPath)
+	return strings.TrimSuffix(inputPath, ext) + "." + part + ext
+}
@@ -0,0 +1,158 @@
+package dataset
+
+import (
+	"cutl/internal/editor"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseSplitParts(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []SplitPart
+		err  bool
+	}{
+		{spec: "80,20", want: []SplitPart{{"train", 0.8}, {"test", 0.2}}},
+		{spec: "8,1,1", want: []SplitPart{{"train", 0.8}, {"dev", 0.1}, {"test", 0.1}}},
+		{spec: "a=1, b=1, c=2", want: []SplitPart{{"a", 0.25}, {"b", 0.25}, {"c", 0.5}}},
+		{spec: "1,1,1,1", want: []SplitPart{{"part1", 0.25}, {"part2", 0.25}, {"part3", 0.25}, {"part4", 0.25}}},
+		{spec: "1", err: true},
+		{spec: "train=0.8,test=0", err: true},
+		{spec: "train=x,test=1", err: true},
+		{spec: "a=1,a=2", err: true},
+		{spec: "a/b=1,c=1", err: true},
+	}
+	for _, test := range tests {
+		got, err := ParseSplitParts(test.spec)
+		if (err != nil) != test.err {
+			t.Errorf("ParseSplitParts(%q) error = %v", test.spec, err)
+			continue
+		}
+		for i := range got {
+			// Ratios are normalized by division; compare them rounded.
+			got[i].Ratio = float64(int(got[i].Ratio*1000+0.5)) / 1000
+		}
+		if !test.err && !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseSplitParts(%q) = %v, want %v", test.spec, got, test.want)
+		}
+	}
+}
+
+// splitRows builds n rows with a doc id shared by every group of size rows
+// and the given label for the first labeled rows, "neg" for the rest.
+func splitRows(t *testing.T, n, group, labeled int, label string) []editor.Entry {
+	lines := make([]string, n)
+	for i := range lines {
+		value := "neg"
+		if i < labeled {
+			value = label
+		}
+		lines[i] = fmt.Sprintf(`{"i":%d,"doc":%d,"label":%q}`, i, i/group, value)
+	}
+	return decodeEntries(t, lines...)
+}
+
+func TestSplit(t *testing.T) {
+	halves := []SplitPart{{"a", 0.5}, {"b", 0.5}}
+	tests := []struct {
+		name    string
+		entries []editor.Entry
+		opts    SplitOptions
+		sizes   []int
+		// labels are the expected label counts per part, when stratified.
+		labels []map[string]int
+	}{
+		{
+			name:    "ratios",
+			entries: splitRows(t, 10, 1, 0, ""),
+			opts:    SplitOptions{Parts: []SplitPart{{"train", 0.8}, {"dev", 0.1}, {"test", 0.1}}, Seed: 1},
+			sizes:   []int{8, 1, 1},
+		},
+		{
+			name:    "groups stay together",
+			entries: splitRows(t, 20, 2, 0, ""),
+			opts:    SplitOptions{Parts: halves, Group: ".doc", Seed: 7},
+			sizes:   []int{10, 10},
+		},
+		{
+			name:    "uneven groups are counted by size",
+			entries: splitRows(t, 12, 3, 0, ""),
+			opts:    SplitOptions{Parts: []SplitPart{{"a", 0.75}, {"b", 0.25}}, Group: ".doc", Seed: 3},
+			sizes:   []int{9, 3},
+		},
+		{
+			name:    "stratified",
+			entries: splitRows(t, 12, 1, 8, "pos"),
+			opts:    SplitOptions{Parts: []SplitPart{{"a", 0.75}, {"b", 0.25}}, Stratify: ".label", Seed: 42},
+			sizes:   []int{9, 3},
+			labels:  []map[string]int{{"pos": 6, "neg": 3}, {"pos": 2, "neg": 1}},
+		},
+		{
+			name:    "stratified groups",
+			entries: splitRows(t, 16, 2, 8, "pos"),
+			opts:    SplitOptions{Parts: halves, Stratify: ".label", Group: ".doc", Seed: 5},
+			sizes:   []int{8, 8},
+			labels:  []map[string]int{{"pos": 4, "neg": 4}, {"pos": 4, "neg": 4}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Split(test.entries, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := Split(test.entries, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, again) {
+				t.Error("the same seed gave a different split")
+			}
+
+			docs := make(map[any]int)
+			seen := make(map[int]bool)
+			for p, part := range result {
+				if len(part) != test.sizes[p] {
+					t.Errorf("part %d has %d rows, want %d", p, len(part), test.sizes[p])
+				}
+				labels := make(map[string]int)
+				for i, entry := range part {
+					if i > 0 && part[i-1].Line >= entry.Line {
+						t.Errorf("part %d is not in input order", p)
+					}
+					if seen[entry.Line] {
+						t.Errorf("line %d is in several parts", entry.Line)
+					}
+					seen[entry.Line] = true
+					row := entry.Data.(map[string]any)
+					labels[row["label"].(string)]++
+					if test.opts.Group == "" {
+						continue
+					}
+					if other, ok := docs[row["doc"]]; ok && other != p {
+						t.Errorf("doc %v is split over parts %d and %d", row["doc"], other, p)
+					}
+					docs[row["doc"]] = p
+				}
+				if test.labels != nil && !reflect.DeepEqual(labels, test.labels[p]) {
+					t.Errorf("part %d has labels %v, want %v", p, labels, test.labels[p])
+				}
+			}
+			if len(seen) != len(test.entries) {
+				t.Errorf("%d of %d rows were assigned", len(seen), len(test.entries))
+			}
+		})
+	}
+}
+
+func TestSplitUngroupedRows(t *testing.T) {
+	entries := decodeEntries(t, `{"doc":null}`, `{"doc":null}`, `{}`, `{}`)
+	result, err := Split(entries, SplitOptions{Parts: []SplitPart{{"a", 0.5}, {"b", 0.5}}, Group: ".doc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result[0]) != 2 || len(result[1]) != 2 {
+		t.Errorf("rows without a group key were grouped: %d and %d rows", len(result[0]), len(result[1]))
+	}
+}
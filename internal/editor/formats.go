@@ -0,0 +1,257 @@
+package editor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// Format is a file format entries can be read from and written to.
+type Format string
+
+const (
+	FormatJSONL Format = "jsonl"
+	FormatCSV   Format = "csv"
+	FormatTSV   Format = "tsv"
+	FormatJSON  Format = "json"
+)
+
+// FormatOf picks the format from the file extension. Everything that is not
+// CSV, TSV or a JSON array is read as JSONL.
+func FormatOf(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".tsv", ".tab":
+		return FormatTSV
+	case ".json":
+		return FormatJSON
+	}
+	return FormatJSONL
+}
+
+// LoadOptions control how CSV and TSV cells become JSON values.
+type LoadOptions struct {
+	// Strings keeps every cell as a string instead of inferring numbers,
+	// booleans, null and nested JSON.
+	Strings bool
+	// EmptyAsNull turns empty cells into null instead of "".
+	EmptyAsNull bool
+}
+
+// Load reads entries in the format of the file. For CSV and TSV files the
+// header is returned as well, so the columns can be written back in their
+// original order.
+func Load(filePath string, options LoadOptions) ([]Entry, []string, error) {
+	switch FormatOf(filePath) {
+	case FormatCSV:
+		return loadDelimited(filePath, ',', options)
+	case FormatTSV:
+		return loadDelimited(filePath, '\t', options)
+	case FormatJSON:
+		entries, err := loadJSONArray(filePath)
+		return entries, nil, err
+	}
+	entries, err := LoadJSONL(filePath)
+	return entries, nil, err
+}
+
+func loadDelimited(filePath string, comma rune, options LoadOptions) ([]Entry, []string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(bufio.NewReader(file))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	if comma == '\t' {
+		reader.LazyQuotes = true
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: missing header: %w", filePath, err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	var entries []Entry
+	for {
+		record, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+		row := make(map[string]any, len(header))
+		for i, name := range header {
+			cell := ""
+			if i < len(record) {
+				cell = record[i]
+			}
+			row[name] = convertCell(cell, options)
+		}
+		entries = append(entries, Entry{Data: row, Line: len(entries) + 1})
+	}
+	log.Debugf("Loaded %d rows from %s.", len(entries), filePath)
+	return entries, header, nil
+}
+
+// convertCell infers the JSON type of a cell. Only numbers that are written
+// back exactly as read become numbers, so identifiers like 007, long IDs
+// beyond float64 precision and spellings like 1.50 or 1e3 stay strings.
+func convertCell(cell string, options LoadOptions) any {
+	if cell == "" {
+		if options.EmptyAsNull {
+			return nil
+		}
+		return ""
+	}
+	if options.Strings {
+		return cell
+	}
+
+	switch cell {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	// NaN is a word in tabular data, not a number.
+	if number, err := strconv.ParseFloat(cell, 64); err == nil && strconv.FormatFloat(number, 'f', -1, 64) == cell && cell != "NaN" {
+		return number
+	}
+	if first := cell[0]; first == '[' || first == '{' {
+		var nested any
+		if err := json.Unmarshal([]byte(cell), &nested); err == nil {
+			return nested
+		}
+	}
+	return cell
+}
+
+func loadJSONArray(filePath string) ([]Entry, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var values []any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%s: expected a JSON array: %w", filePath, err)
+	}
+	entries := make([]Entry, len(values))
+	for i, value := range values {
+		entries[i] = Entry{Data: value, Line: i + 1}
+	}
+	log.Debugf("Loaded %d array elements from %s.", len(entries), filePath)
+	return entries, nil
+}
+
+// Write stores entries in the format of the file. CSV and TSV columns follow
+// header, with keys missing from it appended in alphabetical order.
+func Write(filePath string, entries []Entry, header []string) error {
+	switch format := FormatOf(filePath); format {
+	case FormatCSV, FormatTSV:
+		comma := ','
+		if format == FormatTSV {
+			comma = '\t'
+		}
+		return writeDelimited(filePath, entries, header, comma)
+	case FormatJSON:
+		return writeJSONArray(filePath, entries)
+	}
+	return WriteJSONL(filePath, entries)
+}
+
+func writeDelimited(filePath string, entries []Entry, header []string, comma rune) error {
+	columns := append([]string{}, header...)
+	known := make(map[string]struct{}, len(columns))
+	for _, column := range columns {
+		known[column] = struct{}{}
+	}
+	var extra []string
+	for _, entry := range entries {
+		row, ok := entry.Data.(map[string]any)
+		if !ok {
+			return fmt.Errorf("line %d is not an object and cannot be written as a table row", entry.Line)
+		}
+		for key := range row {
+			if _, ok := known[key]; !ok {
+				known[key] = struct{}{}
+				extra = append(extra, key)
+			}
+		}
+	}
+	sort.Strings(extra)
+	columns = append(columns, extra...)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma = comma
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		row := entry.Data.(map[string]any)
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			cell, err := formatCell(row[column])
+			if err != nil {
+				return fmt.Errorf("line %d: %w", entry.Line, err)
+			}
+			record[i] = cell
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, buf.Bytes(), 0644)
+}
+
+func formatCell(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	encoded, err := json.Marshal(value)
+	return string(encoded), err
+}
+
+func writeJSONArray(filePath string, entries []Entry) error {
+	values := make([]any, len(entries))
+	for i, entry := range entries {
+		values[i] = entry.Data
+	}
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, append(data, '\n'), 0644)
+}
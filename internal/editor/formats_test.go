@@ -0,0 +1,54 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConvertCell(t *testing.T) {
+	tests := []struct {
+		cell    string
+		options LoadOptions
+		want    any
+	}{
+		{cell: "42", want: float64(42)},
+		{cell: "-3.25", want: -3.25},
+		{cell: "0", want: float64(0)},
+		{cell: "0.5", want: 0.5},
+		{cell: "1.50", want: "1.50"},
+		{cell: "1e3", want: "1e3"},
+		{cell: "+5", want: "+5"},
+		{cell: "007", want: "007"},
+		{cell: "-007", want: "-007"},
+		{cell: ".5", want: ".5"},
+		{cell: "12345678901234567890", want: "12345678901234567890"},
+		{cell: "Inf", want: "Inf"},
+		{cell: "NaN", want: "NaN"},
+		{cell: "0x1F", want: "0x1F"},
+		{cell: "true", want: true},
+		{cell: "null", want: nil},
+		{cell: `{"a":1}`, want: map[string]any{"a": float64(1)}},
+		{cell: "[1,", want: "[1,"},
+		{cell: "", want: ""},
+		{cell: "", options: LoadOptions{EmptyAsNull: true}, want: nil},
+		{cell: "42", options: LoadOptions{Strings: true}, want: "42"},
+	}
+	for _, test := range tests {
+		got := convertCell(test.cell, test.options)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("convertCell(%q, %+v) = %#v, want %#v", test.cell, test.options, got, test.want)
+		}
+	}
+}
+
+func TestConvertCellRoundTrip(t *testing.T) {
+	for _, cell := range []string{"1.50", "1e3", "+5", "12345678901234567890", "3.14159", "-0.001", "100"} {
+		formatted, err := formatCell(convertCell(cell, LoadOptions{}))
+		if err != nil {
+			t.Fatalf("formatCell(%q): %v", cell, err)
+		}
+		if formatted != cell {
+			t.Errorf("%q is written back as %q", cell, formatted)
+		}
+	}
+}
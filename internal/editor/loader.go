@@ -13,6 +13,23 @@ type Entry struct {
 	Line int
 }
 
+// ReadLines returns the lines of a file as LoadJSONL reads them, so the
+// line of an entry indexes them.
+func ReadLines(filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
 func LoadJSONL(filePath string) ([]Entry, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
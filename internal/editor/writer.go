@@ -3,6 +3,7 @@ package editor
 import (
 	"bufio"
 	"encoding/json"
+	"io"
 	"os"
 
 	"github.com/charmbracelet/log"
@@ -16,7 +17,18 @@ func WriteJSONL(filePath string, entries []Entry) error {
 	}
 	defer file.Close()
 
-	writer := bufio.NewWriter(file)
+	if err := WriteJSONLTo(file, entries); err != nil {
+		return err
+	}
+
+	log.Debugf("Erfolgreich %d JSON-Objekte in %s geschrieben.", len(entries), filePath)
+
+	return nil
+}
+
+// WriteJSONLTo writes one JSON object per line to w.
+func WriteJSONLTo(w io.Writer, entries []Entry) error {
+	writer := bufio.NewWriter(w)
 
 	for _, entry := range entries {
 		data, err := json.Marshal(entry.Data)
@@ -36,12 +48,10 @@ func WriteJSONL(filePath string, entries []Entry) error {
 		}
 	}
 
-	if err = writer.Flush(); err != nil {
+	if err := writer.Flush(); err != nil {
 		log.Error("Failed to flush JSONL writer:", "error", err)
 		return err
 	}
 
-	log.Debugf("Erfolgreich %d JSON-Objekte in %s geschrieben.", len(entries), filePath)
-
 	return nil
 }
@@ -0,0 +1,28 @@
+package export
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"fmt"
+)
+
+// Project applies a jq expression to every entry, e.g. `{text, label}`.
+// Every output of the expression becomes one row; entries without output
+// are dropped.
+func Project(entries []editor.Entry, expr string) ([]editor.Entry, error) {
+	q, err := query.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	projected := make([]editor.Entry, 0, len(entries))
+	for _, entry := range entries {
+		values, err := q.All(entry.Data)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", entry.Line, err)
+		}
+		for _, value := range values {
+			projected = append(projected, editor.Entry{Data: value, Line: entry.Line})
+		}
+	}
+	return projected, nil
+}
@@ -0,0 +1,239 @@
+package export
+
+import (
+	"bytes"
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Format is a tabular output format for the current columns.
+type Format string
+
+const (
+	CSV      Format = "csv"
+	TSV      Format = "tsv"
+	Markdown Format = "markdown"
+	JSON     Format = "json"
+)
+
+// Formats lists the supported formats in the order shown in help texts.
+var Formats = []Format{CSV, TSV, Markdown, JSON}
+
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "csv":
+		return CSV, nil
+	case "tsv":
+		return TSV, nil
+	case "markdown", "md":
+		return Markdown, nil
+	case "json":
+		return JSON, nil
+	}
+	return "", fmt.Errorf("unknown format %q (use csv, tsv, markdown or json)", name)
+}
+
+// FormatForPath guesses the format from a file extension. ok is false for
+// paths that don't name a tabular format, such as .jsonl.
+func FormatForPath(path string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return CSV, true
+	case ".tsv", ".tab":
+		return TSV, true
+	case ".md", ".markdown":
+		return Markdown, true
+	case ".json":
+		return JSON, true
+	}
+	return "", false
+}
+
+var simplePath = regexp.MustCompile(`^(\.[A-Za-z_][A-Za-z0-9_]*)+$`)
+
+// Header derives a column header from its query: simple paths such as
+// `.meta.source` lose the leading dot, everything else is kept verbatim.
+func Header(column string) string {
+	if simplePath.MatchString(column) {
+		return column[1:]
+	}
+	return column
+}
+
+// Table holds the column values of the exported entries. Values are the raw
+// jq results, nil where a column produced no output.
+type Table struct {
+	Headers []string
+	Rows    [][]any
+}
+
+// Columns evaluates the column queries over the entries. headers may be nil
+// to derive them from the queries.
+func Columns(entries []editor.Entry, columns []string, headers []string) (*Table, error) {
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns to export")
+	}
+	if headers != nil && len(headers) != len(columns) {
+		return nil, fmt.Errorf("%d headers given for %d columns", len(headers), len(columns))
+	}
+
+	queries := make([]*query.Query, len(columns))
+	for i, column := range columns {
+		q, err := query.Compile(column)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", column, err)
+		}
+		queries[i] = q
+	}
+
+	table := &Table{Headers: headers, Rows: make([][]any, 0, len(entries))}
+	if table.Headers == nil {
+		for _, column := range columns {
+			table.Headers = append(table.Headers, Header(column))
+		}
+	}
+	for _, entry := range entries {
+		row := make([]any, len(queries))
+		for i, q := range queries {
+			// Cells of failing queries stay empty, like ERR cells in the table.
+			value, _, _ := q.First(entry.Data)
+			row[i] = value
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// Write renders the table in the given format.
+func (t *Table) Write(w io.Writer, format Format) error {
+	switch format {
+	case CSV, TSV:
+		writer := csv.NewWriter(w)
+		if format == TSV {
+			writer.Comma = '\t'
+		}
+		if err := writer.Write(t.Headers); err != nil {
+			return err
+		}
+		for _, row := range t.Rows {
+			if err := writer.Write(t.cells(row)); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case Markdown:
+		return t.writeMarkdown(w)
+	case JSON:
+		return t.writeJSON(w)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// WriteFile renders the table into a file.
+func (t *Table) WriteFile(path string, format Format) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := t.Write(file, format); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// cells formats a row for the text formats. Unlike table cells, numbers keep
+// their full precision.
+func (t *Table) cells(row []any) []string {
+	cells := make([]string, len(row))
+	for i, value := range row {
+		switch v := value.(type) {
+		case nil:
+		case float64:
+			cells[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			cells[i] = query.Format(v)
+		}
+	}
+	return cells
+}
+
+// writeJSON writes an array of objects keyed by header. The objects are
+// assembled by hand to keep the column order, which maps would lose.
+func (t *Table) writeJSON(w io.Writer) error {
+	keys := make([][]byte, len(t.Headers))
+	for i, header := range t.Headers {
+		key, err := json.Marshal(header)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+
+	var b bytes.Buffer
+	b.WriteString("[")
+	for r, row := range t.Rows {
+		if r > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  {")
+		for i, value := range row {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.Write(keys[i])
+			b.WriteString(": ")
+			b.Write(encoded)
+		}
+		b.WriteString("}")
+	}
+	if len(t.Rows) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+func (t *Table) writeMarkdown(w io.Writer) error {
+	line := func(cells []string) error {
+		for i, cell := range cells {
+			cells[i] = markdownEscaper.Replace(cell)
+		}
+		_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+		return err
+	}
+
+	if err := line(append([]string{}, t.Headers...)); err != nil {
+		return err
+	}
+	separator := make([]string, len(t.Headers))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	if err := line(separator); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		if err := line(t.cells(row)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
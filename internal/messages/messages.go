@@ -1,11 +1,23 @@
 package messages
 
-import "cutl/internal/editor"
+import (
+	"cutl/internal/audit"
+	"cutl/internal/config"
+	"cutl/internal/dataset"
+	"cutl/internal/editor"
+	"cutl/internal/review"
+	"cutl/internal/schema"
+	"cutl/internal/stats"
+)
 
 type ColumnQueryChanged struct {
 	Queries []string
 }
 
+type HighlightRulesChanged struct {
+	Rules []config.HighlightRule
+}
+
 type FilterQueryChanged struct {
 	Query string
 }
@@ -20,8 +32,58 @@ type FilterPromptResult struct {
 	Error error
 }
 
+// InputFileLoaded carries the loaded entries. Header is the column order of
+// CSV and TSV input, nil for JSON formats.
 type InputFileLoaded struct {
 	Content []editor.Entry
+	Header  []string
+}
+
+// InputFilesMerged carries the table built from several inputs. Conflicts
+// are keys whose differing versions were all kept for the user to resolve.
+type InputFilesMerged struct {
+	Content   []editor.Entry
+	Header    []string
+	Conflicts []dataset.Conflict
+	Dropped   int
+}
+
+// DiffLoaded carries one row per change between two versions of a file
+// and the old version the accepted changes are applied to.
+type DiffLoaded struct {
+	Content   []editor.Entry
+	Header    []string
+	Old       []editor.Entry
+	Unchanged int
+}
+
+// AuditLogLoaded carries the operations logged for the file.
+type AuditLogLoaded struct {
+	Records []audit.Record
+	Error   error
+}
+
+// WritePreviewReady carries what writing the table would change on disk.
+type WritePreviewReady struct {
+	Preview dataset.WritePreview
+	Error   error
+}
+
+// ReplacePreviewReady carries what a find/replace would change in the
+// target rows; Samples lists the first affected rows.
+type ReplacePreviewReady struct {
+	Generation int
+	Targets    int
+	Rows       int
+	Matches    int
+	Samples    []ReplaceSample
+	Error      error
+}
+
+// ReplaceSample is one affected row and the strings that would change.
+type ReplaceSample struct {
+	Line         int
+	Replacements []dataset.Replacement
 }
 
 type InputFileLoadError struct {
@@ -37,6 +99,28 @@ type InputFileWriteError struct {
 	Error error
 }
 
+// EntriesExported reports a subset written to another file. Moved lists the
+// row keys (see dataset.RowKeys) of the rows to remove from the table when
+// the rows were moved; lines may have shifted while the file was written.
+type EntriesExported struct {
+	Path  string
+	Count int
+	Moved []string
+}
+
+// SplitWritten reports the files written by a split, one per part.
+type SplitWritten struct {
+	Parts  []string
+	Paths  []string
+	Counts []int
+	Seed   int64
+}
+
+type InputFileSavedAs struct {
+	Path  string
+	Count int
+}
+
 type SortByColumn struct {
 	ColumnIndex int
 }
@@ -50,3 +134,61 @@ type EditApplied struct {
 type EditApplyError struct {
 	Error error
 }
+
+type StatsComputed struct {
+	Generation int
+	Count      int
+	Profiles   []stats.FieldProfile
+}
+
+type FacetComputed struct {
+	Generation int
+	Column     int
+	Values     []stats.FacetValue
+	Error      error
+}
+
+type GroupByComputed struct {
+	Generation int
+	Result     *stats.GroupResult
+	Error      error
+}
+
+type DuplicatesFound struct {
+	Generation int
+	Clusters   []dataset.Cluster
+	Error      error
+}
+
+type SchemaLoaded struct {
+	Validator *schema.Validator
+}
+
+type SchemaLoadError struct {
+	Error error
+}
+
+type ValidationCompleted struct {
+	Violations map[int][]schema.Violation
+}
+
+type SchemaInferred struct {
+	Schema map[string]any
+}
+
+// InferredSchemaSaved reports writing an inferred schema; with Attach it is
+// to be used for validation.
+type InferredSchemaSaved struct {
+	Path   string
+	Attach bool
+	Error  error
+}
+
+type ReviewsLoaded struct {
+	Reviews map[int]review.Status
+	Sidecar *review.Sidecar
+	Error   error
+}
+
+// RestoreSession is sent once the input file is loaded and shown.
+type RestoreSession struct{}
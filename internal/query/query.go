@@ -0,0 +1,122 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/itchyny/gojq"
+)
+
+// Query is a compiled jq expression that can be evaluated against many
+// entries without re-parsing it for every row.
+type Query struct {
+	expr string
+	code *gojq.Code
+}
+
+func Compile(expr string) (*Query, error) {
+	parsed, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	code, err := gojq.Compile(parsed)
+	if err != nil {
+		return nil, err
+	}
+	return &Query{expr: expr, code: code}, nil
+}
+
+func (q *Query) String() string {
+	return q.expr
+}
+
+// First returns the first value the query produces for data. The boolean is
+// false when the query yields no output at all.
+func (q *Query) First(data any) (any, bool, error) {
+	iter := q.code.Run(data)
+	v, ok := iter.Next()
+	if !ok {
+		return nil, false, nil
+	}
+	if err, isErr := v.(error); isErr {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+// All collects every value the query produces for data.
+func (q *Query) All(data any) ([]any, error) {
+	var values []any
+	iter := q.code.Run(data)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			return values, nil
+		}
+		if err, isErr := v.(error); isErr {
+			return values, err
+		}
+		values = append(values, v)
+	}
+}
+
+// Truthy reports whether the first result is neither false nor null, using
+// the same semantics as jq's select. Errors and empty output count as false.
+func (q *Query) Truthy(data any) bool {
+	v, ok, err := q.First(data)
+	if err != nil || !ok {
+		return false
+	}
+	return IsTruthy(v)
+}
+
+func IsTruthy(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	default:
+		return true
+	}
+}
+
+// Format renders a jq result the way it is shown in table cells: whole
+// numbers without decimals, arrays and objects as compact JSON.
+func Format(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case float64:
+		return FormatFloat(v)
+	case []interface{}, map[string]interface{}:
+		if jsonBytes, err := json.Marshal(v); err == nil {
+			return string(jsonBytes)
+		}
+		return fmt.Sprintf("%v", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func FormatFloat(value float64) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Sprintf("%v", value)
+	}
+
+	if isWholeNumber(value) {
+		return strconv.FormatInt(int64(math.Round(value)), 10)
+	}
+
+	rounded := math.Round(value*1000) / 1000
+	return strconv.FormatFloat(rounded, 'f', 3, 64)
+}
+
+func isWholeNumber(value float64) bool {
+	const epsilon = 1e-9
+	return math.Abs(value-math.Round(value)) < epsilon
+}
@@ -0,0 +1,114 @@
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type State string
+
+const (
+	Unreviewed State = "unreviewed"
+	Accepted   State = "accepted"
+	Rejected   State = "rejected"
+	Flagged    State = "flagged"
+)
+
+// States lists every review state in display order.
+var States = []State{Unreviewed, Accepted, Rejected, Flagged}
+
+func ParseState(text string) (State, error) {
+	for _, state := range States {
+		if string(state) == text {
+			return state, nil
+		}
+	}
+	return "", fmt.Errorf("unknown review state %q (use unreviewed, accepted, rejected or flagged)", text)
+}
+
+// Status is the review outcome of one row. Notes are mostly used to explain
+// why a row was flagged.
+type Status struct {
+	State State  `json:"state"`
+	Note  string `json:"note,omitempty"`
+}
+
+// FromField reads a status stored inside a row, either as a bare state
+// string or as a {"state": ..., "note": ...} object.
+func FromField(value any) (Status, bool) {
+	switch v := value.(type) {
+	case string:
+		state, err := ParseState(v)
+		if err != nil || state == Unreviewed {
+			return Status{}, false
+		}
+		return Status{State: state}, true
+	case map[string]any:
+		text, _ := v["state"].(string)
+		state, err := ParseState(text)
+		if err != nil || state == Unreviewed {
+			return Status{}, false
+		}
+		note, _ := v["note"].(string)
+		return Status{State: state, Note: note}, true
+	}
+	return Status{}, false
+}
+
+// Sidecar keeps review statuses next to the data file, keyed by the row
+// keys of the file (see dataset.RowKeys), so the data itself stays untouched
+// and identical rows each have their own status.
+type Sidecar struct {
+	Rows map[string]Status `json:"rows"`
+}
+
+// SidecarPath places the sidecar next to the input, data.jsonl becoming
+// data.cutl-review.json.
+func SidecarPath(jsonlPath string) string {
+	return strings.TrimSuffix(jsonlPath, filepath.Ext(jsonlPath)) + ".cutl-review.json"
+}
+
+// LoadSidecar reads a sidecar; a missing file is an empty sidecar.
+func LoadSidecar(path string) (*Sidecar, error) {
+	sidecar := &Sidecar{Rows: make(map[string]Status)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sidecar, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, sidecar); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if sidecar.Rows == nil {
+		sidecar.Rows = make(map[string]Status)
+	}
+	return sidecar, nil
+}
+
+func (s *Sidecar) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Set records the status of the row with the given key. Unreviewed rows are
+// removed from the sidecar.
+func (s *Sidecar) Set(key string, status Status) {
+	if status.State == Unreviewed || status.State == "" {
+		delete(s.Rows, key)
+		return
+	}
+	s.Rows[key] = status
+}
+
+func (s *Sidecar) Get(key string) (Status, bool) {
+	status, ok := s.Rows[key]
+	return status, ok
+}
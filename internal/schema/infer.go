@@ -0,0 +1,243 @@
+package schema
+
+import (
+	"cutl/internal/editor"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	draft2020 = "https://json-schema.org/draft/2020-12/schema"
+	// Strings with at most this many distinct values become enums, provided
+	// every value occurs several times on average.
+	maxEnumValues     = 10
+	minEnumOccurrence = 3
+)
+
+// typeOrder keeps the `type` lists of inferred schemas stable.
+var typeOrder = []string{"object", "array", "string", "integer", "number", "boolean", "null"}
+
+type node struct {
+	count       int
+	types       map[string]int
+	objects     int
+	properties  map[string]*node
+	items       *node
+	strings     int
+	stringSet   map[string]struct{}
+	tooManyStrs bool
+}
+
+func newNode() *node {
+	return &node{types: make(map[string]int)}
+}
+
+// Infer derives a JSON Schema (draft 2020-12) describing all entries. Keys
+// present in every object become required, low-cardinality strings enums.
+func Infer(entries []editor.Entry) map[string]any {
+	root := newNode()
+	for _, entry := range entries {
+		root.add(entry.Data)
+	}
+
+	schema := root.schema()
+	schema["$schema"] = draft2020
+	return schema
+}
+
+func (n *node) add(value any) {
+	n.count++
+	switch v := value.(type) {
+	case nil:
+		n.types["null"]++
+	case bool:
+		n.types["boolean"]++
+	case float64:
+		if v == float64(int64(v)) {
+			n.types["integer"]++
+		} else {
+			n.types["number"]++
+		}
+	case int, int64:
+		n.types["integer"]++
+	case string:
+		n.types["string"]++
+		n.strings++
+		if !n.tooManyStrs {
+			if n.stringSet == nil {
+				n.stringSet = make(map[string]struct{})
+			}
+			n.stringSet[v] = struct{}{}
+			if len(n.stringSet) > maxEnumValues {
+				n.tooManyStrs = true
+				n.stringSet = nil
+			}
+		}
+	case []interface{}:
+		n.types["array"]++
+		if n.items == nil {
+			n.items = newNode()
+		}
+		for _, item := range v {
+			n.items.add(item)
+		}
+	case map[string]interface{}:
+		n.types["object"]++
+		n.objects++
+		if n.properties == nil {
+			n.properties = make(map[string]*node)
+		}
+		for key, child := range v {
+			prop, ok := n.properties[key]
+			if !ok {
+				prop = newNode()
+				n.properties[key] = prop
+			}
+			prop.add(child)
+		}
+	}
+}
+
+func (n *node) schema() map[string]any {
+	schema := make(map[string]any)
+
+	types := n.typeNames()
+	switch len(types) {
+	case 0:
+		return schema
+	case 1:
+		schema["type"] = types[0]
+	default:
+		schema["type"] = types
+	}
+
+	if n.objects > 0 {
+		properties := make(map[string]any, len(n.properties))
+		var required []string
+		for key, prop := range n.properties {
+			properties[key] = prop.schema()
+			if prop.count == n.objects {
+				required = append(required, key)
+			}
+		}
+		sort.Strings(required)
+		schema["properties"] = properties
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	}
+
+	if n.items != nil && n.items.count > 0 {
+		schema["items"] = n.items.schema()
+	}
+
+	if n.isEnum() {
+		values := make([]string, 0, len(n.stringSet))
+		for v := range n.stringSet {
+			values = append(values, v)
+		}
+		sort.Strings(values)
+		enum := make([]any, 0, len(values)+1)
+		for _, v := range values {
+			enum = append(enum, v)
+		}
+		if n.types["null"] > 0 {
+			enum = append(enum, nil)
+		}
+		schema["enum"] = enum
+	}
+
+	return schema
+}
+
+// isEnum only applies to fields that are strings (or null) throughout, since
+// an enum would otherwise reject the other types.
+func (n *node) isEnum() bool {
+	if n.tooManyStrs || len(n.stringSet) == 0 {
+		return false
+	}
+	if n.strings+n.types["null"] != n.count {
+		return false
+	}
+	return n.strings >= len(n.stringSet)*minEnumOccurrence
+}
+
+func (n *node) typeNames() []string {
+	var types []string
+	for _, t := range typeOrder {
+		if n.types[t] == 0 {
+			continue
+		}
+		// Integers are numbers, so a mix of both is simply a number.
+		if t == "integer" && n.types["number"] > 0 {
+			continue
+		}
+		types = append(types, t)
+	}
+	return types
+}
+
+// Tree renders a schema as an indented outline, one property per line.
+func Tree(schema map[string]any) []string {
+	var lines []string
+	writeTree(&lines, "(root)", schema, true, 0)
+	return lines
+}
+
+func writeTree(lines *[]string, name string, schema map[string]any, required bool, depth int) {
+	indent := strings.Repeat("  ", depth)
+	description := describe(schema)
+	if !required {
+		description += " (optional)"
+	}
+	*lines = append(*lines, fmt.Sprintf("%s%s: %s", indent, name, description))
+
+	if properties, ok := schema["properties"].(map[string]any); ok {
+		requiredKeys := make(map[string]bool)
+		if keys, ok := schema["required"].([]string); ok {
+			for _, key := range keys {
+				requiredKeys[key] = true
+			}
+		}
+		keys := make([]string, 0, len(properties))
+		for key := range properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if child, ok := properties[key].(map[string]any); ok {
+				writeTree(lines, key, child, requiredKeys[key], depth+1)
+			}
+		}
+	}
+
+	if items, ok := schema["items"].(map[string]any); ok {
+		writeTree(lines, "[]", items, true, depth+1)
+	}
+}
+
+func describe(schema map[string]any) string {
+	var description string
+	switch t := schema["type"].(type) {
+	case string:
+		description = t
+	case []string:
+		description = strings.Join(t, " | ")
+	default:
+		description = "any"
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		values := make([]string, len(enum))
+		for i, v := range enum {
+			if v == nil {
+				values[i] = "null"
+			} else {
+				values[i] = fmt.Sprintf("%q", v)
+			}
+		}
+		description += " enum[" + strings.Join(values, ", ") + "]"
+	}
+	return description
+}
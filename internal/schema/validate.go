@@ -0,0 +1,121 @@
+package schema
+
+import (
+	"cutl/internal/editor"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+)
+
+// Violation is a single schema error. Path is a JSON pointer into the entry,
+// e.g. `/spans/0/2`; it is empty when the entry as a whole is invalid.
+type Violation struct {
+	Path    string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// Validator checks entries against a compiled JSON Schema.
+type Validator struct {
+	path   string
+	schema *jsonschema.Schema
+}
+
+// Load compiles the JSON Schema stored at path.
+func Load(path string) (*Validator, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiled, err := compiler.Compile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema %s: %w", path, err)
+	}
+	return &Validator{path: path, schema: compiled}, nil
+}
+
+func (v *Validator) Path() string {
+	return v.path
+}
+
+// Validate returns all violations for a single value, or nil if it is valid.
+func (v *Validator) Validate(data any) []Violation {
+	err := v.schema.Validate(normalize(data))
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return []Violation{{Message: err.Error()}}
+	}
+
+	var violations []Violation
+	for _, unit := range validationErr.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+		// Group and reference errors only say that a nested schema failed;
+		// the specific causes are listed separately.
+		switch unit.Error.Kind.(type) {
+		case *kind.Group, *kind.Reference:
+			continue
+		}
+		violations = append(violations, Violation{
+			Path:    unit.InstanceLocation,
+			Message: unit.Error.String(),
+		})
+	}
+	if len(violations) == 0 {
+		violations = append(violations, Violation{Message: validationErr.Error()})
+	}
+	return violations
+}
+
+// ValidateEntries validates all entries and returns the violations of the
+// invalid ones keyed by line.
+func (v *Validator) ValidateEntries(entries []editor.Entry) map[int][]Violation {
+	result := make(map[int][]Violation)
+	for _, entry := range entries {
+		if violations := v.Validate(entry.Data); len(violations) > 0 {
+			result[entry.Line] = violations
+		}
+	}
+	return result
+}
+
+// normalize converts values produced by edits (such as int64) into the types
+// encoding/json would have decoded, which is what the validator expects.
+func normalize(value any) any {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		clone := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			clone[key] = normalize(val)
+		}
+		return clone
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, val := range v {
+			clone[i] = normalize(val)
+		}
+		return clone
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,306 @@
+package script
+
+import (
+	"bytes"
+	"cutl/internal/dataset"
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OpDelete    = "delete"
+	OpEdit      = "edit"
+	OpTransform = "transform"
+	OpReplace   = "replace"
+)
+
+// Script is a recorded sequence of operations that can be replayed on
+// another file of the same shape.
+type Script struct {
+	Source string `yaml:"source,omitempty"`
+	Steps  []Step `yaml:"steps"`
+}
+
+// Step is one operation and the rows it applies to: the rows matching the
+// jq filter Where (all rows when empty), or, for rows picked by hand, the
+// rows whose key is listed in Rows (see dataset.RowKeys: the content hash,
+// plus the occurrence for later copies of identical rows). Edits set the paths in Set to
+// their values; transforms replace every row by the output of Expr;
+// replaces run the find/replace in Replace.
+type Step struct {
+	Op      string         `yaml:"op"`
+	Where   string         `yaml:"where,omitempty"`
+	Rows    []string       `yaml:"rows,omitempty"`
+	Set     map[string]any `yaml:"set,omitempty"`
+	Expr    string         `yaml:"expr,omitempty"`
+	Replace *Replace       `yaml:"replace,omitempty"`
+}
+
+// Replace is the find/replace of a replace step, see dataset.ReplaceOptions.
+type Replace struct {
+	Find       string `yaml:"find"`
+	With       string `yaml:"with"`
+	In         string `yaml:"in,omitempty"`
+	Regex      bool   `yaml:"regex,omitempty"`
+	IgnoreCase bool   `yaml:"ignore_case,omitempty"`
+	Word       bool   `yaml:"word,omitempty"`
+}
+
+func NewReplace(options dataset.ReplaceOptions) *Replace {
+	replace := Replace(options)
+	return &replace
+}
+
+func (r Replace) Options() dataset.ReplaceOptions {
+	return dataset.ReplaceOptions(r)
+}
+
+// Describe summarizes the step for reports, e.g. `edit .label where .x`.
+func (s Step) Describe() string {
+	text := s.Op
+	switch s.Op {
+	case OpEdit:
+		text += " " + strings.Join(s.setPaths(), ", ")
+	case OpTransform:
+		text += " " + s.Expr
+	case OpReplace:
+		if s.Replace != nil {
+			text += " " + s.Replace.Options().String()
+		}
+	}
+	switch {
+	case len(s.Rows) > 0:
+		text += fmt.Sprintf(" on %d picked rows", len(s.Rows))
+	case s.Where != "":
+		text += " where " + s.Where
+	default:
+		text += " on all rows"
+	}
+	return text
+}
+
+func (s Step) setPaths() []string {
+	paths := make([]string, 0, len(s.Set))
+	for path := range s.Set {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func Load(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var script Script
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i, step := range script.Steps {
+		if err := step.validate(); err != nil {
+			return nil, fmt.Errorf("%s: step %d: %w", path, i+1, err)
+		}
+	}
+	return &script, nil
+}
+
+func (s *Script) Save(path string) error {
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(s); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buffer.Bytes(), 0644)
+}
+
+func (s Step) validate() error {
+	switch s.Op {
+	case OpDelete:
+	case OpEdit:
+		if len(s.Set) == 0 {
+			return fmt.Errorf("edit without set")
+		}
+		for path := range s.Set {
+			if !strings.HasPrefix(path, ".") {
+				return fmt.Errorf("set path %q must start with '.'", path)
+			}
+		}
+	case OpTransform:
+		if s.Expr == "" {
+			return fmt.Errorf("transform without expr")
+		}
+	case OpReplace:
+		if s.Replace == nil || s.Replace.Find == "" {
+			return fmt.Errorf("replace without find")
+		}
+	default:
+		return fmt.Errorf("unknown op %q (use delete, edit, transform or replace)", s.Op)
+	}
+	return nil
+}
+
+// StepReport tells what a step did: how many rows it selected and how many
+// of them it deleted or changed.
+type StepReport struct {
+	Step    Step
+	Matched int
+	Changed int
+}
+
+// Report is the outcome of a replay.
+type Report struct {
+	Steps      []StepReport
+	RowsBefore int
+	RowsAfter  int
+}
+
+// Apply replays the steps on the entries in order and returns the result;
+// the input is left untouched. A failing step stops the replay.
+func Apply(s *Script, entries []editor.Entry) ([]editor.Entry, Report, error) {
+	report := Report{RowsBefore: len(entries)}
+	current := append([]editor.Entry(nil), entries...)
+	for i, step := range s.Steps {
+		next, stepReport, err := applyStep(step, current)
+		if err != nil {
+			return nil, report, fmt.Errorf("step %d (%s): %w", i+1, step.Describe(), err)
+		}
+		report.Steps = append(report.Steps, stepReport)
+		current = next
+	}
+	for i := range current {
+		current[i].Line = i + 1
+	}
+	report.RowsAfter = len(current)
+	return current, report, nil
+}
+
+func applyStep(step Step, entries []editor.Entry) ([]editor.Entry, StepReport, error) {
+	report := StepReport{Step: step}
+	if err := step.validate(); err != nil {
+		return nil, report, err
+	}
+	selected, err := selector(step, entries)
+	if err != nil {
+		return nil, report, err
+	}
+
+	var update func(any) (any, error)
+	switch step.Op {
+	case OpEdit:
+		if update, err = assignments(step.Set); err != nil {
+			return nil, report, err
+		}
+	case OpTransform:
+		transform, err := query.Compile(step.Expr)
+		if err != nil {
+			return nil, report, fmt.Errorf("expr: %w", err)
+		}
+		update = func(data any) (any, error) {
+			value, ok, err := transform.First(data)
+			if err == nil && !ok {
+				err = fmt.Errorf("no output")
+			}
+			return value, err
+		}
+	case OpReplace:
+		replacer, err := dataset.NewReplacer(step.Replace.Options())
+		if err != nil {
+			return nil, report, err
+		}
+		update = func(data any) (any, error) {
+			value, _, err := replacer.Replace(data)
+			return value, err
+		}
+	}
+
+	result := make([]editor.Entry, 0, len(entries))
+	for i, entry := range entries {
+		if !selected(i) {
+			result = append(result, entry)
+			continue
+		}
+		report.Matched++
+		if step.Op == OpDelete {
+			report.Changed++
+			continue
+		}
+		data, err := update(entry.Data)
+		if err != nil {
+			return nil, report, fmt.Errorf("line %d: %w", entry.Line, err)
+		}
+		if !reflect.DeepEqual(data, entry.Data) {
+			report.Changed++
+		}
+		entry.Data = data
+		result = append(result, entry)
+	}
+	return result, report, nil
+}
+
+// selector matches the entries, by index, on the row keys of the step or
+// on its filter.
+func selector(step Step, entries []editor.Entry) (func(int) bool, error) {
+	if len(step.Rows) > 0 {
+		picked := make(map[string]struct{}, len(step.Rows))
+		for _, key := range step.Rows {
+			picked[key] = struct{}{}
+		}
+		rows := make([]any, len(entries))
+		for i, entry := range entries {
+			rows[i] = entry.Data
+		}
+		keys := dataset.RowKeys(rows)
+		return func(i int) bool {
+			_, ok := picked[keys[i]]
+			return ok
+		}, nil
+	}
+	if step.Where == "" {
+		return func(int) bool { return true }, nil
+	}
+	where, err := query.Compile(step.Where)
+	if err != nil {
+		return nil, fmt.Errorf("where: %w", err)
+	}
+	return func(i int) bool { return where.Truthy(entries[i].Data) }, nil
+}
+
+// assignments turns the set map into one jq assignment per path, so nested
+// paths are created the way jq creates them.
+func assignments(set map[string]any) (func(any) (any, error), error) {
+	var queries []*query.Query
+	for _, path := range (Step{Set: set}).setPaths() {
+		value, err := json.Marshal(set[path])
+		if err != nil {
+			return nil, fmt.Errorf("set %s: %w", path, err)
+		}
+		q, err := query.Compile(fmt.Sprintf("%s = %s", path, value))
+		if err != nil {
+			return nil, fmt.Errorf("set %s: %w", path, err)
+		}
+		queries = append(queries, q)
+	}
+	return func(data any) (any, error) {
+		for _, q := range queries {
+			value, _, err := q.First(data)
+			if err != nil {
+				return nil, err
+			}
+			data = value
+		}
+		return data, nil
+	}, nil
+}
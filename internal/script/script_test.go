@@ -0,0 +1,247 @@
+package script
+
+import (
+	"cutl/internal/dataset"
+	"cutl/internal/editor"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decodeEntries reads one JSON row per argument, numbered from 1.
+func decodeEntries(t *testing.T, lines ...string) []editor.Entry {
+	t.Helper()
+	entries := make([]editor.Entry, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &entries[i].Data); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		entries[i].Line = i + 1
+	}
+	return entries
+}
+
+// encodeEntries writes the rows back as compact JSON, keys sorted.
+func encodeEntries(t *testing.T, entries []editor.Entry) []string {
+	t.Helper()
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		encoded, err := json.Marshal(entry.Data)
+		if err != nil {
+			t.Fatalf("encode line %d: %v", entry.Line, err)
+		}
+		lines[i] = string(encoded)
+	}
+	return lines
+}
+
+func TestApply(t *testing.T) {
+	input := []string{
+		`{"text":"a cat","label":"pet","score":0.9}`,
+		`{"text":"a dog","label":"pet","score":0.2}`,
+		`{"text":"noise","label":"junk","score":0.1}`,
+		`{"text":"noise","label":"junk","score":0.1}`,
+		`{"text":"noise","label":"junk","score":0.1}`,
+	}
+	noise := dataset.RowHash(decodeEntries(t, input[2])[0].Data)
+
+	tests := []struct {
+		name    string
+		steps   []Step
+		want    []string
+		reports []StepReport
+	}{
+		{
+			name:  "delete where",
+			steps: []Step{{Op: OpDelete, Where: `.label == "junk"`}},
+			want:  input[:2],
+			reports: []StepReport{
+				{Matched: 3, Changed: 3},
+			},
+		},
+		{
+			name: "picked copies of identical rows",
+			steps: []Step{
+				{Op: OpDelete, Rows: []string{dataset.RowKey(noise, 1), dataset.RowKey(noise, 2)}},
+			},
+			want:    input[:3],
+			reports: []StepReport{{Matched: 2, Changed: 2}},
+		},
+		{
+			name: "picked plain hash is the first copy",
+			steps: []Step{
+				{Op: OpEdit, Rows: []string{noise}, Set: map[string]any{".label": "kept"}},
+			},
+			want: []string{
+				input[0], input[1],
+				`{"label":"kept","score":0.1,"text":"noise"}`,
+				input[3], input[4],
+			},
+			reports: []StepReport{{Matched: 1, Changed: 1}},
+		},
+		{
+			name: "edit set",
+			steps: []Step{
+				{Op: OpEdit, Where: ".score > 0.5", Set: map[string]any{".label": "cat", ".meta.checked": true}},
+			},
+			want: []string{
+				`{"label":"cat","meta":{"checked":true},"score":0.9,"text":"a cat"}`,
+				input[1], input[2], input[3], input[4],
+			},
+			reports: []StepReport{{Matched: 1, Changed: 1}},
+		},
+		{
+			name: "steps see the rows of the steps before",
+			steps: []Step{
+				{Op: OpDelete, Where: `.label == "junk"`},
+				{Op: OpTransform, Expr: "{text, pet: (.label == \"pet\")}"},
+				{Op: OpReplace, Replace: &Replace{Find: "a ", With: "the ", In: ".text"}},
+				{Op: OpEdit, Where: `.text == "the dog"`, Set: map[string]any{".pet": true}},
+			},
+			want: []string{
+				`{"pet":true,"text":"the cat"}`,
+				`{"pet":true,"text":"the dog"}`,
+			},
+			reports: []StepReport{
+				{Matched: 3, Changed: 3},
+				{Matched: 2, Changed: 2},
+				{Matched: 2, Changed: 2},
+				{Matched: 1, Changed: 0},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries := decodeEntries(t, input...)
+			result, report, err := Apply(&Script{Steps: test.steps}, entries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := encodeEntries(t, result); !reflect.DeepEqual(got, encodeEntries(t, decodeEntries(t, test.want...))) {
+				t.Errorf("result = %v, want %v", got, test.want)
+			}
+			for i, entry := range result {
+				if entry.Line != i+1 {
+					t.Errorf("row %d has line %d", i, entry.Line)
+				}
+			}
+			if report.RowsBefore != len(input) || report.RowsAfter != len(test.want) {
+				t.Errorf("rows %d -> %d, want %d -> %d", report.RowsBefore, report.RowsAfter, len(input), len(test.want))
+			}
+			if len(report.Steps) != len(test.reports) {
+				t.Fatalf("%d step reports, want %d", len(report.Steps), len(test.reports))
+			}
+			for i, step := range report.Steps {
+				if step.Matched != test.reports[i].Matched || step.Changed != test.reports[i].Changed {
+					t.Errorf("step %d matched %d, changed %d; want %d, %d", i+1,
+						step.Matched, step.Changed, test.reports[i].Matched, test.reports[i].Changed)
+				}
+			}
+			if got := encodeEntries(t, entries); !reflect.DeepEqual(got, encodeEntries(t, decodeEntries(t, input...))) {
+				t.Errorf("the input rows were changed: %v", got)
+			}
+		})
+	}
+}
+
+func TestApplyStops(t *testing.T) {
+	entries := decodeEntries(t, `{"n":1}`, `{"n":"x"}`)
+	tests := []struct {
+		name  string
+		steps []Step
+		err   string
+		done  int
+	}{
+		{
+			name:  "bad filter",
+			steps: []Step{{Op: OpDelete, Where: ".n |||"}},
+			err:   "step 1",
+		},
+		{
+			name: "failing row",
+			steps: []Step{
+				{Op: OpEdit, Set: map[string]any{".ok": true}},
+				{Op: OpTransform, Expr: ".n + 1"},
+				{Op: OpDelete},
+			},
+			err:  "step 2",
+			done: 1,
+		},
+		{
+			name:  "transform without output",
+			steps: []Step{{Op: OpTransform, Expr: "empty"}},
+			err:   "no output",
+		},
+		{
+			name:  "invalid step",
+			steps: []Step{{Op: "rename"}},
+			err:   "unknown op",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, report, err := Apply(&Script{Steps: test.steps}, entries)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("error = %v, want one containing %q", err, test.err)
+			}
+			if result != nil {
+				t.Errorf("a failed replay returned %d rows", len(result))
+			}
+			if len(report.Steps) != test.done {
+				t.Errorf("%d steps reported, want %d", len(report.Steps), test.done)
+			}
+		})
+	}
+}
+
+func TestStepValidate(t *testing.T) {
+	tests := []struct {
+		step Step
+		err  bool
+	}{
+		{step: Step{Op: OpDelete}},
+		{step: Step{Op: OpEdit, Set: map[string]any{".a": 1}}},
+		{step: Step{Op: OpEdit}, err: true},
+		{step: Step{Op: OpEdit, Set: map[string]any{"a": 1}}, err: true},
+		{step: Step{Op: OpTransform, Expr: "."}},
+		{step: Step{Op: OpTransform}, err: true},
+		{step: Step{Op: OpReplace, Replace: &Replace{Find: "a"}}},
+		{step: Step{Op: OpReplace}, err: true},
+		{step: Step{Op: OpReplace, Replace: &Replace{With: "b"}}, err: true},
+		{step: Step{}, err: true},
+	}
+	for _, test := range tests {
+		if err := test.step.validate(); (err != nil) != test.err {
+			t.Errorf("validate(%+v) = %v", test.step, err)
+		}
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.yaml")
+	script := &Script{Source: "in.jsonl", Steps: []Step{
+		{Op: OpDelete, Rows: []string{"abc", "abc#1"}},
+		{Op: OpEdit, Where: ".x", Set: map[string]any{".y": "z"}},
+		{Op: OpReplace, Replace: &Replace{Find: "a", With: "b", Word: true}},
+	}}
+	if err := script.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, script) {
+		t.Errorf("loaded %+v, want %+v", loaded, script)
+	}
+
+	if err := os.WriteFile(path, []byte("steps:\n  - op: edit\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "step 1") {
+		t.Errorf("Load of an invalid step = %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package spans
+
+import (
+	"cutl/internal/query"
+	"fmt"
+	"sort"
+	"unicode"
+)
+
+// DefaultTextPath and DefaultSpanPaths locate spaCy-style annotations when
+// no paths are configured. The first span path that exists is used.
+const DefaultTextPath = ".text"
+
+var DefaultSpanPaths = []string{".spans", ".entities"}
+
+// Span is a labeled character range. Offsets count Unicode code points like
+// Python string indices do, so they match what spaCy writes.
+type Span struct {
+	Start int
+	End   int
+	Label string
+}
+
+// Document is the text of one entry together with its spans. Objects records
+// whether the spans were stored as {start,end,label} objects rather than
+// [start,end,label] triples, so they can be written back in the same shape.
+type Document struct {
+	Text      string
+	Spans     []Span
+	SpansPath string
+	Objects   bool
+}
+
+// Extract reads the text and spans of an entry. It returns nil without error
+// when the entry has no string at textPath or no spans array. An empty
+// spansPath tries DefaultSpanPaths.
+func Extract(data any, textPath, spansPath string) (*Document, error) {
+	return extract(data, textPath, spansPath, false)
+}
+
+// Open is like Extract but also returns a document without spans when the
+// entry has text but no spans array yet, so annotation can start from
+// scratch. New spans go to spansPath, or the first default path.
+func Open(data any, textPath, spansPath string) (*Document, error) {
+	return extract(data, textPath, spansPath, true)
+}
+
+func extract(data any, textPath, spansPath string, create bool) (*Document, error) {
+	if textPath == "" {
+		textPath = DefaultTextPath
+	}
+	textValue, err := lookup(data, textPath)
+	if err != nil {
+		return nil, err
+	}
+	text, ok := textValue.(string)
+	if !ok {
+		return nil, nil
+	}
+
+	candidates := DefaultSpanPaths
+	if spansPath != "" {
+		candidates = []string{spansPath}
+	}
+	for _, path := range candidates {
+		value, err := lookup(data, path)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		spans, objects, err := Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return &Document{Text: text, Spans: spans, SpansPath: path, Objects: objects}, nil
+	}
+
+	if !create {
+		return nil, nil
+	}
+	return &Document{Text: text, SpansPath: candidates[0]}, nil
+}
+
+func lookup(data any, path string) (any, error) {
+	q, err := query.Compile(path)
+	if err != nil {
+		return nil, err
+	}
+	value, _, err := q.First(data)
+	if err != nil {
+		// Paths into the wrong type simply mean the entry has no spans.
+		return nil, nil
+	}
+	return value, nil
+}
+
+// Parse reads an array of [start, end, label] triples or {start, end, label}
+// objects. The second result reports whether objects were used.
+func Parse(value any) ([]Span, bool, error) {
+	items, ok := value.([]any)
+	if !ok {
+		return nil, false, fmt.Errorf("spans must be an array")
+	}
+
+	spans := make([]Span, 0, len(items))
+	objects := false
+	for i, item := range items {
+		var start, end, label any
+		switch v := item.(type) {
+		case []any:
+			if len(v) < 2 {
+				return nil, false, fmt.Errorf("span %d needs at least start and end", i)
+			}
+			start, end = v[0], v[1]
+			if len(v) > 2 {
+				label = v[2]
+			}
+		case map[string]any:
+			objects = true
+			start, end, label = v["start"], v["end"], v["label"]
+		default:
+			return nil, false, fmt.Errorf("span %d must be an array or object", i)
+		}
+
+		s, okStart := toInt(start)
+		e, okEnd := toInt(end)
+		if !okStart || !okEnd {
+			return nil, false, fmt.Errorf("span %d has non-integer offsets", i)
+		}
+		labelText, _ := label.(string)
+		spans = append(spans, Span{Start: s, End: e, Label: labelText})
+	}
+	return spans, objects, nil
+}
+
+func toInt(value any) (int, bool) {
+	switch v := value.(type) {
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		return int(v), true
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+// Encode turns spans back into JSON values, sorted by offset, in the shape
+// they were read in.
+func Encode(spans []Span, objects bool) []any {
+	sorted := Sorted(spans)
+	values := make([]any, len(sorted))
+	for i, s := range sorted {
+		if objects {
+			values[i] = map[string]any{"start": int64(s.Start), "end": int64(s.End), "label": s.Label}
+		} else {
+			values[i] = []any{int64(s.Start), int64(s.End), s.Label}
+		}
+	}
+	return values
+}
+
+// At returns the index of the first span covering the character at pos, or
+// -1 when there is none.
+func (d *Document) At(pos int) int {
+	for i, s := range d.Spans {
+		if pos >= s.Start && pos < s.End {
+			return i
+		}
+	}
+	return -1
+}
+
+// Set labels the range [start, end). Spans overlapping the range are
+// replaced, since entity spans must not overlap.
+func (d *Document) Set(start, end int, label string) {
+	d.Remove(start, end)
+	d.Spans = Sorted(append(d.Spans, Span{Start: start, End: end, Label: label}))
+}
+
+// Remove deletes the spans overlapping the range [start, end) and returns
+// how many were removed.
+func (d *Document) Remove(start, end int) int {
+	kept := d.Spans[:0:0]
+	for _, s := range d.Spans {
+		if s.Start < end && start < s.End {
+			continue
+		}
+		kept = append(kept, s)
+	}
+	removed := len(d.Spans) - len(kept)
+	d.Spans = kept
+	return removed
+}
+
+// Labels returns the distinct labels of the spans, sorted.
+func Labels(spans []Span) []string {
+	seen := make(map[string]struct{})
+	var labels []string
+	for _, s := range spans {
+		if _, ok := seen[s.Label]; ok || s.Label == "" {
+			continue
+		}
+		seen[s.Label] = struct{}{}
+		labels = append(labels, s.Label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// Sorted returns a copy of spans ordered by start, then longest first.
+func Sorted(spans []Span) []Span {
+	sorted := make([]Span, len(spans))
+	copy(sorted, spans)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Start != sorted[j].Start {
+			return sorted[i].Start < sorted[j].Start
+		}
+		return sorted[i].End > sorted[j].End
+	})
+	return sorted
+}
+
+// Check reports spans that cannot be placed on the text: offsets out of
+// range, boundaries inside a word or on whitespace, and overlaps.
+func Check(text string, spans []Span) []string {
+	runes := []rune(text)
+	var warnings []string
+	for i, s := range spans {
+		name := fmt.Sprintf("span %d [%d, %d %s]", i, s.Start, s.End, s.Label)
+		if s.Start < 0 || s.End > len(runes) || s.Start >= s.End {
+			warnings = append(warnings, fmt.Sprintf("%s: offsets out of range for text of length %d", name, len(runes)))
+			continue
+		}
+		if s.Start > 0 && isWordRune(runes[s.Start-1]) && isWordRune(runes[s.Start]) {
+			warnings = append(warnings, fmt.Sprintf("%s: start splits the token %q", name, tokenAt(runes, s.Start)))
+		}
+		if s.End < len(runes) && isWordRune(runes[s.End-1]) && isWordRune(runes[s.End]) {
+			warnings = append(warnings, fmt.Sprintf("%s: end splits the token %q", name, tokenAt(runes, s.End-1)))
+		}
+		if unicode.IsSpace(runes[s.Start]) || unicode.IsSpace(runes[s.End-1]) {
+			warnings = append(warnings, fmt.Sprintf("%s: starts or ends with whitespace", name))
+		}
+		for j := 0; j < i; j++ {
+			other := spans[j]
+			if s.Start < other.End && other.Start < s.End {
+				warnings = append(warnings, fmt.Sprintf("%s: overlaps span %d", name, j))
+				break
+			}
+		}
+	}
+	return warnings
+}
+
+// Valid reports whether a span fits the text at all; invalid spans are left
+// out when rendering.
+func (s Span) Valid(textLength int) bool {
+	return s.Start >= 0 && s.End <= textLength && s.Start < s.End
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func tokenAt(runes []rune, pos int) string {
+	start, end := pos, pos
+	for start > 0 && isWordRune(runes[start-1]) {
+		start--
+	}
+	for end < len(runes) && isWordRune(runes[end]) {
+		end++
+	}
+	return string(runes[start:end])
+}
@@ -0,0 +1,69 @@
+package stats
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FacetValue is one distinct value of a facet. Literal is the JSON encoding
+// of the value and can be used verbatim inside a jq filter.
+type FacetValue struct {
+	Label   string
+	Literal string
+	Count   int
+}
+
+// Facet groups entries by the first result of expr and returns the distinct
+// values sorted by frequency. Entries for which expr yields nothing are
+// counted as null, entries where it fails are skipped.
+func Facet(entries []editor.Entry, expr string) ([]FacetValue, error) {
+	q, err := query.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]*FacetValue)
+	for _, entry := range entries {
+		v, _, err := q.First(entry.Data)
+		if err != nil {
+			continue
+		}
+		literal, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+		key := string(literal)
+		if fv, ok := counts[key]; ok {
+			fv.Count++
+			continue
+		}
+		counts[key] = &FacetValue{Label: query.Format(v), Literal: key, Count: 1}
+	}
+
+	values := make([]FacetValue, 0, len(counts))
+	for _, fv := range counts {
+		values = append(values, *fv)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Label < values[j].Label
+	})
+	return values, nil
+}
+
+// FacetFilter builds a jq condition that matches entries whose expr value is
+// one of the given JSON literals. Like Facet it treats an expression without
+// output as null, so the null count can be drilled into too.
+func FacetFilter(expr string, literals []string) string {
+	comparisons := make([]string, len(literals))
+	for i, literal := range literals {
+		comparisons[i] = ". == " + literal
+	}
+	return fmt.Sprintf("[%s] | if length == 0 then [null] else . end | any(%s)", expr, strings.Join(comparisons, " or "))
+}
@@ -0,0 +1,64 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFacet(t *testing.T) {
+	entries := decodeEntries(t,
+		`{"label":"pos"}`,
+		`{"label":"neg"}`,
+		`{"label":"pos"}`,
+		`{"label":null}`,
+		`{}`,
+		`{"label":1}`,
+		`"not an object"`,
+	)
+	values, err := Facet(entries, ".label")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FacetValue{
+		{Label: "null", Literal: "null", Count: 2},
+		{Label: "pos", Literal: `"pos"`, Count: 2},
+		{Label: "1", Literal: "1", Count: 1},
+		{Label: "neg", Literal: `"neg"`, Count: 1},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("Facet = %+v, want %+v", values, want)
+	}
+
+	if _, err := Facet(entries, ".label |||"); err == nil {
+		t.Error("a bad facet expression was accepted")
+	}
+}
+
+func TestFacetFilter(t *testing.T) {
+	entries := decodeEntries(t,
+		`{"label":"pos"}`,
+		`{"label":"neg"}`,
+		`{"label":null}`,
+		`{}`,
+		`{"spans":[]}`,
+		`{"spans":[{"label":"pos"},{"label":"neg"}]}`,
+	)
+	tests := []struct {
+		expr     string
+		literals []string
+		want     []int
+	}{
+		{expr: ".label", literals: []string{`"pos"`}, want: []int{1}},
+		{expr: ".label", literals: []string{`"pos"`, `"neg"`}, want: []int{1, 2}},
+		{expr: ".label", literals: []string{"null"}, want: []int{3, 4, 5, 6}},
+		{expr: ".spans[]?.label", literals: []string{"null"}, want: []int{1, 2, 3, 4, 5}},
+		{expr: ".spans[]?.label", literals: []string{`"neg"`}, want: []int{6}},
+		{expr: ".label", literals: []string{`"other"`}, want: nil},
+	}
+	for _, test := range tests {
+		got := matching(t, entries, FacetFilter(test.expr, test.literals))
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("FacetFilter(%s, %v) matches lines %v, want %v", test.expr, test.literals, got, test.want)
+		}
+	}
+}
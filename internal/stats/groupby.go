@@ -0,0 +1,202 @@
+package stats
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Aggregate is one summary column of a group-by. Kind is one of sum, avg,
+// min, max or reduce; count is parsed too but stands for the group size,
+// which is always shown, so it is not passed to GroupBy. For reduce the
+// expression receives the array of all rows in the group, for the others it
+// is evaluated per row.
+type Aggregate struct {
+	Label string
+	Kind  string
+	Expr  string
+}
+
+var aggregateKinds = map[string]bool{
+	"count":  true,
+	"sum":    true,
+	"avg":    true,
+	"min":    true,
+	"max":    true,
+	"reduce": true,
+}
+
+// ParseAggregate reads `count`, `sum(EXPR)`, `avg(EXPR)`, `min(EXPR)`,
+// `max(EXPR)` or `reduce(EXPR)`.
+func ParseAggregate(text string) (Aggregate, error) {
+	text = strings.TrimSpace(text)
+	if text == "count" {
+		return Aggregate{Label: "count", Kind: "count"}, nil
+	}
+
+	open := strings.Index(text, "(")
+	if open <= 0 || !strings.HasSuffix(text, ")") {
+		return Aggregate{}, fmt.Errorf("aggregate %q must look like sum(.field)", text)
+	}
+	kind := strings.TrimSpace(text[:open])
+	if !aggregateKinds[kind] || kind == "count" {
+		return Aggregate{}, fmt.Errorf("unknown aggregate %q (use count, sum, avg, min, max or reduce)", kind)
+	}
+	expr := strings.TrimSpace(text[open+1 : len(text)-1])
+	if expr == "" {
+		return Aggregate{}, fmt.Errorf("aggregate %q has no expression", text)
+	}
+	if _, err := query.Compile(expr); err != nil {
+		return Aggregate{}, fmt.Errorf("aggregate %q: %v", text, err)
+	}
+	return Aggregate{Label: text, Kind: kind, Expr: expr}, nil
+}
+
+// Group is one distinct key of a group-by together with its aggregates, in
+// the same order as the requested Aggregate list. Count is the number of
+// rows that produced the key; a row producing it several times, like
+// `.spans[].label` with two spans of a label, counts once, as the
+// aggregates see it once.
+type Group struct {
+	Label   string
+	Literal string
+	Count   int
+	Values  []string
+}
+
+// GroupResult holds the groups sorted by size, largest first.
+type GroupResult struct {
+	Expr       string
+	Aggregates []Aggregate
+	Groups     []Group
+}
+
+type groupAccumulator struct {
+	label   string
+	literal string
+	count   int
+	rows    []any
+}
+
+// GroupBy assigns every entry to the group of each value groupExpr produces
+// for it and computes the aggregates per group.
+func GroupBy(entries []editor.Entry, groupExpr string, aggregates []Aggregate) (*GroupResult, error) {
+	groupQuery, err := query.Compile(groupExpr)
+	if err != nil {
+		return nil, err
+	}
+
+	aggQueries := make([]*query.Query, len(aggregates))
+	for i, agg := range aggregates {
+		if aggQueries[i], err = query.Compile(agg.Expr); err != nil {
+			return nil, err
+		}
+	}
+
+	groups := make(map[string]*groupAccumulator)
+	var order []string
+	for _, entry := range entries {
+		keys, err := groupQuery.All(entry.Data)
+		if err != nil {
+			continue
+		}
+		if len(keys) == 0 {
+			keys = []any{nil}
+		}
+		seen := make(map[string]struct{}, len(keys))
+		for _, key := range keys {
+			literal, err := json.Marshal(key)
+			if err != nil {
+				continue
+			}
+			acc, ok := groups[string(literal)]
+			if !ok {
+				acc = &groupAccumulator{label: query.Format(key), literal: string(literal)}
+				groups[string(literal)] = acc
+				order = append(order, string(literal))
+			}
+			if _, dup := seen[string(literal)]; dup {
+				continue
+			}
+			seen[string(literal)] = struct{}{}
+			acc.count++
+			acc.rows = append(acc.rows, entry.Data)
+		}
+	}
+
+	result := &GroupResult{Expr: groupExpr, Aggregates: aggregates}
+	for _, literal := range order {
+		acc := groups[literal]
+		group := Group{Label: acc.label, Literal: acc.literal, Count: acc.count}
+		for i, agg := range aggregates {
+			group.Values = append(group.Values, aggregate(agg, aggQueries[i], acc.rows))
+		}
+		result.Groups = append(result.Groups, group)
+	}
+	sort.SliceStable(result.Groups, func(i, j int) bool {
+		return result.Groups[i].Count > result.Groups[j].Count
+	})
+	return result, nil
+}
+
+func aggregate(agg Aggregate, q *query.Query, rows []any) string {
+	switch agg.Kind {
+	case "reduce":
+		v, ok, err := q.First(rows)
+		if err != nil {
+			return "ERR:EXEC"
+		}
+		if !ok {
+			return ""
+		}
+		return query.Format(v)
+	}
+
+	var numbers []float64
+	for _, row := range rows {
+		values, err := q.All(row)
+		if err != nil {
+			continue
+		}
+		for _, v := range values {
+			if n, ok := toFloat(v); ok {
+				numbers = append(numbers, n)
+			}
+		}
+	}
+	if len(numbers) == 0 {
+		return ""
+	}
+
+	var result float64
+	switch agg.Kind {
+	case "sum", "avg":
+		for _, n := range numbers {
+			result += n
+		}
+		if agg.Kind == "avg" {
+			result /= float64(len(numbers))
+		}
+	case "min":
+		result = math.Inf(1)
+		for _, n := range numbers {
+			result = math.Min(result, n)
+		}
+	case "max":
+		result = math.Inf(-1)
+		for _, n := range numbers {
+			result = math.Max(result, n)
+		}
+	}
+	return query.FormatFloat(result)
+}
+
+// GroupFilter builds a jq condition matching the entries of one group. Like
+// GroupBy it treats an expression without output as null.
+func GroupFilter(groupExpr, literal string) string {
+	return fmt.Sprintf("[%s] | if length == 0 then [null] else . end | any(.[]; . == %s)", groupExpr, literal)
+}
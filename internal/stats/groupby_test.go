@@ -0,0 +1,142 @@
+package stats
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// decodeEntries reads one JSON row per argument, numbered from 1.
+func decodeEntries(t *testing.T, lines ...string) []editor.Entry {
+	t.Helper()
+	entries := make([]editor.Entry, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &entries[i].Data); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		entries[i].Line = i + 1
+	}
+	return entries
+}
+
+// matching returns the lines of the entries for which filter is true.
+func matching(t *testing.T, entries []editor.Entry, filter string) []int {
+	t.Helper()
+	q, err := query.Compile(filter)
+	if err != nil {
+		t.Fatalf("compile %q: %v", filter, err)
+	}
+	var lines []int
+	for _, entry := range entries {
+		if q.Truthy(entry.Data) {
+			lines = append(lines, entry.Line)
+		}
+	}
+	return lines
+}
+
+func TestParseAggregate(t *testing.T) {
+	tests := []struct {
+		text string
+		want Aggregate
+		err  bool
+	}{
+		{text: "count", want: Aggregate{Label: "count", Kind: "count"}},
+		{text: " sum(.x) ", want: Aggregate{Label: "sum(.x)", Kind: "sum", Expr: ".x"}},
+		{text: "avg( .a.b )", want: Aggregate{Label: "avg( .a.b )", Kind: "avg", Expr: ".a.b"}},
+		{text: "reduce(map(.x) | add)", want: Aggregate{Label: "reduce(map(.x) | add)", Kind: "reduce", Expr: "map(.x) | add"}},
+		{text: "count(.x)", err: true},
+		{text: "median(.x)", err: true},
+		{text: "sum()", err: true},
+		{text: "sum(.x", err: true},
+		{text: "max(.x |||)", err: true},
+	}
+	for _, test := range tests {
+		got, err := ParseAggregate(test.text)
+		if (err != nil) != test.err || got != test.want {
+			t.Errorf("ParseAggregate(%q) = %+v, %v", test.text, got, err)
+		}
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	entries := decodeEntries(t,
+		`{"tags":["a","a"],"x":1}`,
+		`{"tags":["a","b"],"x":3}`,
+		`{"tags":[],"x":5}`,
+		`{"x":7}`,
+	)
+	aggregates := []Aggregate{
+		{Label: "sum(.x)", Kind: "sum", Expr: ".x"},
+		{Label: "avg(.x)", Kind: "avg", Expr: ".x"},
+		{Label: "min(.x)", Kind: "min", Expr: ".x"},
+		{Label: "max(.missing)", Kind: "max", Expr: ".missing"},
+		{Label: "reduce(length)", Kind: "reduce", Expr: "length"},
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		want []Group
+	}{
+		{
+			name: "a row repeating a key counts once",
+			expr: ".tags[]?",
+			want: []Group{
+				{Label: "a", Literal: `"a"`, Count: 2, Values: []string{"4", "2", "1", "", "2"}},
+				{Label: "null", Literal: "null", Count: 2, Values: []string{"12", "6", "5", "", "2"}},
+				{Label: "b", Literal: `"b"`, Count: 1, Values: []string{"3", "3", "3", "", "1"}},
+			},
+		},
+		{
+			name: "missing and null values share a group",
+			expr: ".tags | length",
+			want: []Group{
+				{Label: "2", Literal: "2", Count: 2, Values: []string{"4", "2", "1", "", "2"}},
+				{Label: "0", Literal: "0", Count: 2, Values: []string{"12", "6", "5", "", "2"}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := GroupBy(entries, test.expr, aggregates)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.Groups, test.want) {
+				t.Errorf("groups = %+v, want %+v", result.Groups, test.want)
+			}
+		})
+	}
+
+	if _, err := GroupBy(entries, ".x |||", nil); err == nil {
+		t.Error("a bad group expression was accepted")
+	}
+}
+
+func TestGroupFilter(t *testing.T) {
+	entries := decodeEntries(t,
+		`{"tags":["a","a"]}`,
+		`{"tags":["a","b"]}`,
+		`{"tags":[]}`,
+		`{}`,
+		`{"tags":[null]}`,
+	)
+	tests := []struct {
+		literal string
+		want    []int
+	}{
+		{literal: `"a"`, want: []int{1, 2}},
+		{literal: `"b"`, want: []int{2}},
+		{literal: "null", want: []int{3, 4, 5}},
+		{literal: `"c"`, want: nil},
+	}
+	for _, test := range tests {
+		got := matching(t, entries, GroupFilter(".tags[]?", test.literal))
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("GroupFilter(%s) matches lines %v, want %v", test.literal, got, test.want)
+		}
+	}
+}
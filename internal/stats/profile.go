@@ -0,0 +1,272 @@
+package stats
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+const (
+	topValueCount    = 5
+	histogramBuckets = 8
+	// Distinct values are tracked exactly up to this limit to bound memory on
+	// high-cardinality fields such as free text.
+	maxTrackedValues = 10000
+)
+
+// ValueCount is a single value together with how often it occurred.
+type ValueCount struct {
+	Value string
+	Count int
+}
+
+// NumberSummary describes the numeric values found at a path.
+type NumberSummary struct {
+	Count       int
+	Min         float64
+	Max         float64
+	Mean        float64
+	Percentiles map[int]float64
+}
+
+// Histogram buckets string lengths into equally wide ranges.
+type Histogram struct {
+	Min     int
+	Max     int
+	Buckets []int
+}
+
+// FieldProfile summarises every value found at one key path.
+type FieldProfile struct {
+	Path              string
+	Present           int
+	Total             int
+	Types             map[string]int
+	Distinct          int
+	DistinctTruncated bool
+	TopValues         []ValueCount
+	Numbers           *NumberSummary
+	StringLengths     *Histogram
+}
+
+// Presence returns the share of entries that contain the path, in percent.
+func (f FieldProfile) Presence() float64 {
+	if f.Total == 0 {
+		return 0
+	}
+	return float64(f.Present) * 100 / float64(f.Total)
+}
+
+type fieldAccumulator struct {
+	path       string
+	present    int
+	types      map[string]int
+	values     map[string]int
+	truncated  bool
+	numbers    []float64
+	strLengths []int
+}
+
+// Profile walks all entries and returns one profile per discovered key path,
+// sorted by path. Array elements are aggregated under `path[]`.
+func Profile(entries []editor.Entry) []FieldProfile {
+	fields := make(map[string]*fieldAccumulator)
+
+	for _, entry := range entries {
+		seen := make(map[string]struct{})
+		walk(entry.Data, "", func(path string, value any) {
+			acc, ok := fields[path]
+			if !ok {
+				acc = &fieldAccumulator{
+					path:   path,
+					types:  make(map[string]int),
+					values: make(map[string]int),
+				}
+				fields[path] = acc
+			}
+			if _, counted := seen[path]; !counted {
+				seen[path] = struct{}{}
+				acc.present++
+			}
+			acc.add(value)
+		})
+	}
+
+	profiles := make([]FieldProfile, 0, len(fields))
+	for _, acc := range fields {
+		profiles = append(profiles, acc.profile(len(entries)))
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Path < profiles[j].Path
+	})
+	return profiles
+}
+
+func walk(value any, path string, visit func(path string, value any)) {
+	if path != "" {
+		visit(path, value)
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			walk(child, path+"."+key, visit)
+		}
+	case []interface{}:
+		for _, child := range v {
+			walk(child, path+"[]", visit)
+		}
+	}
+}
+
+// TypeName returns the jq type name of a decoded JSON value.
+func TypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, int, int64, float32, int32:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+func (a *fieldAccumulator) add(value any) {
+	typeName := TypeName(value)
+	a.types[typeName]++
+
+	switch v := value.(type) {
+	case []interface{}, map[string]interface{}:
+		// Containers are described by their children.
+		return
+	case string:
+		a.strLengths = append(a.strLengths, utf8.RuneCountInString(v))
+	default:
+		if number, ok := toFloat(v); ok {
+			a.numbers = append(a.numbers, number)
+		}
+	}
+
+	key := query.Format(value)
+	if _, ok := a.values[key]; ok || len(a.values) < maxTrackedValues {
+		a.values[key]++
+	} else {
+		a.truncated = true
+	}
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func (a *fieldAccumulator) profile(total int) FieldProfile {
+	profile := FieldProfile{
+		Path:              a.path,
+		Present:           a.present,
+		Total:             total,
+		Types:             a.types,
+		Distinct:          len(a.values),
+		DistinctTruncated: a.truncated,
+		TopValues:         TopValues(a.values, topValueCount),
+	}
+	if len(a.numbers) > 0 {
+		profile.Numbers = summarizeNumbers(a.numbers)
+	}
+	if len(a.strLengths) > 0 {
+		profile.StringLengths = buildHistogram(a.strLengths, histogramBuckets)
+	}
+	return profile
+}
+
+// TopValues returns the n most frequent values, most frequent first. Ties
+// are broken alphabetically so the output is stable. n <= 0 returns all.
+func TopValues(counts map[string]int, n int) []ValueCount {
+	values := make([]ValueCount, 0, len(counts))
+	for value, count := range counts {
+		values = append(values, ValueCount{Value: value, Count: count})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	if n > 0 && len(values) > n {
+		values = values[:n]
+	}
+	return values
+}
+
+func summarizeNumbers(numbers []float64) *NumberSummary {
+	sorted := append([]float64{}, numbers...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, n := range sorted {
+		sum += n
+	}
+
+	summary := &NumberSummary{
+		Count:       len(sorted),
+		Min:         sorted[0],
+		Max:         sorted[len(sorted)-1],
+		Mean:        sum / float64(len(sorted)),
+		Percentiles: make(map[int]float64),
+	}
+	for _, p := range []int{25, 50, 75, 90, 99} {
+		summary.Percentiles[p] = percentile(sorted, p)
+	}
+	return summary
+}
+
+// percentile uses the nearest-rank method on an already sorted slice.
+func percentile(sorted []float64, p int) float64 {
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func buildHistogram(lengths []int, buckets int) *Histogram {
+	hist := &Histogram{Min: lengths[0], Max: lengths[0], Buckets: make([]int, buckets)}
+	for _, l := range lengths {
+		if l < hist.Min {
+			hist.Min = l
+		}
+		if l > hist.Max {
+			hist.Max = l
+		}
+	}
+
+	span := hist.Max - hist.Min + 1
+	for _, l := range lengths {
+		idx := (l - hist.Min) * buckets / span
+		if idx >= buckets {
+			idx = buckets - 1
+		}
+		hist.Buckets[idx]++
+	}
+	return hist
+}
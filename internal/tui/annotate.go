@@ -0,0 +1,366 @@
+package tui
+
+import (
+	"cutl/internal/spans"
+	"cutl/internal/tui/styles"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// annotCell is one rendered rune, or an inline label, of the annotation view.
+type annotCell struct {
+	text   string
+	width  int
+	style  lipgloss.Style
+	cursor bool
+	space  bool
+}
+
+// openAnnotation starts editing the spans of the selected entry. Without a
+// configured label set the labels already used in the file are offered.
+func (m *Model) openAnnotation() error {
+	entry := m.table.SelectedEntry()
+	if entry == nil {
+		return fmt.Errorf("no entry selected")
+	}
+	textPath, spansPath := m.spanPaths()
+	doc, err := spans.Open(entry.Data, textPath, spansPath)
+	if err != nil {
+		return err
+	}
+	if doc == nil || doc.Text == "" {
+		return fmt.Errorf("entry has no text at %s", describeTextPath(textPath))
+	}
+
+	labels := m.spanConfig().Labels
+	if len(labels) == 0 {
+		var all []spans.Span
+		for _, e := range m.table.Entries() {
+			if d, err := spans.Extract(e.Data, textPath, spansPath); err == nil && d != nil {
+				all = append(all, d.Spans...)
+			}
+		}
+		labels = spans.Labels(all)
+		if len(labels) > 9 {
+			labels = labels[:9]
+		}
+	}
+
+	m.annotDoc = doc
+	m.annotLine = entry.Line
+	m.annotLabels = labels
+	m.annotCursor = 0
+	m.annotAnchor = -1
+	m.annotOffset = 0
+	m.state = annotateView
+	if len(labels) == 0 {
+		m.setStatusNeutralMessage("No labels yet, set them with :spans --labels PER,ORG,...", true)
+	}
+	return nil
+}
+
+func (m *Model) moveAnnotCursor(pos int, extend bool) {
+	length := len([]rune(m.annotDoc.Text))
+	if pos < 0 {
+		pos = 0
+	}
+	if pos >= length {
+		pos = length - 1
+	}
+	if extend && m.annotAnchor < 0 {
+		m.annotAnchor = m.annotCursor
+	}
+	m.annotCursor = pos
+}
+
+func (m *Model) toggleAnnotSelection() {
+	if m.annotAnchor >= 0 {
+		m.annotAnchor = -1
+	} else {
+		m.annotAnchor = m.annotCursor
+	}
+}
+
+// annotSelection returns the selected range as [start, end) offsets.
+func (m *Model) annotSelection() (int, int, bool) {
+	if m.annotAnchor < 0 {
+		return 0, 0, false
+	}
+	start, end := m.annotAnchor, m.annotCursor
+	if start > end {
+		start, end = end, start
+	}
+	return start, end + 1, true
+}
+
+// wordEnd moves to the last character of the current or next word.
+func wordEnd(runes []rune, pos int) int {
+	pos++
+	for pos < len(runes) && !isWordChar(runes[pos]) {
+		pos++
+	}
+	for pos+1 < len(runes) && isWordChar(runes[pos+1]) {
+		pos++
+	}
+	return pos
+}
+
+// wordStart moves to the first character of the current or previous word.
+func wordStart(runes []rune, pos int) int {
+	pos--
+	for pos > 0 && !isWordChar(runes[pos]) {
+		pos--
+	}
+	for pos > 0 && isWordChar(runes[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// jumpAnnotSpan moves the cursor to the start of the next or previous span.
+func (m *Model) jumpAnnotSpan(delta int) {
+	sorted := spans.Sorted(m.annotDoc.Spans)
+	if len(sorted) == 0 {
+		return
+	}
+	if delta > 0 {
+		for _, s := range sorted {
+			if s.Start > m.annotCursor {
+				m.moveAnnotCursor(s.Start, false)
+				return
+			}
+		}
+		m.moveAnnotCursor(sorted[0].Start, false)
+		return
+	}
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if sorted[i].Start < m.annotCursor {
+			m.moveAnnotCursor(sorted[i].Start, false)
+			return
+		}
+	}
+	m.moveAnnotCursor(sorted[len(sorted)-1].Start, false)
+}
+
+// assignAnnotLabel labels the selection, trimmed of surrounding whitespace,
+// or relabels the span under the cursor when nothing is selected.
+func (m *Model) assignAnnotLabel(index int) error {
+	if index >= len(m.annotLabels) {
+		return fmt.Errorf("no label on key %d (set labels with :spans --labels)", index+1)
+	}
+	label := m.annotLabels[index]
+
+	if start, end, ok := m.annotSelection(); ok {
+		runes := []rune(m.annotDoc.Text)
+		for start < end && unicode.IsSpace(runes[start]) {
+			start++
+		}
+		for end > start && unicode.IsSpace(runes[end-1]) {
+			end--
+		}
+		if start == end {
+			return fmt.Errorf("selection only contains whitespace")
+		}
+		m.annotDoc.Set(start, end, label)
+		m.annotAnchor = -1
+		return m.saveAnnotation()
+	}
+
+	i := m.annotDoc.At(m.annotCursor)
+	if i < 0 {
+		return fmt.Errorf("select a range with V or SHIFT+←/→ first")
+	}
+	m.annotDoc.Spans[i].Label = label
+	return m.saveAnnotation()
+}
+
+// removeAnnotSpans deletes the spans touching the selection, or the span
+// under the cursor.
+func (m *Model) removeAnnotSpans() error {
+	start, end, ok := m.annotSelection()
+	if !ok {
+		start, end = m.annotCursor, m.annotCursor+1
+	}
+
+	if m.annotDoc.Remove(start, end) == 0 {
+		return fmt.Errorf("no span at the cursor")
+	}
+	m.annotAnchor = -1
+	return m.saveAnnotation()
+}
+
+// saveAnnotation writes the spans back into the entry right away, in the
+// shape they were read in.
+func (m *Model) saveAnnotation() error {
+	encoded, err := json.Marshal(spans.Encode(m.annotDoc.Spans, m.annotDoc.Objects))
+	if err != nil {
+		return err
+	}
+	values := map[string]string{m.annotDoc.SpansPath: string(encoded)}
+	return m.table.UpdateEntries([]int{m.annotLine}, values, true)
+}
+
+func (m *Model) renderAnnotateView() string {
+	detailStyle := styles.DetailPanel
+	innerWidth := m.width - 8
+	if innerWidth > 0 {
+		detailStyle = detailStyle.Copy().Width(innerWidth)
+	} else {
+		detailStyle = detailStyle.Copy()
+	}
+
+	info := styles.InfoLabel.Render(fmt.Sprintf(
+		"Annotate line %d — ←/→ move, W/B word, SHIFT+←/→ or V select, TAB span, 1-9 label, X remove, ESC done",
+		m.annotLine,
+	))
+
+	labels := make([]string, 0, len(m.annotLabels))
+	for i, label := range m.annotLabels {
+		style := lipgloss.NewStyle().Background(spanLabelColor(label)).Foreground(lipgloss.Color("231"))
+		labels = append(labels, styles.CommandLabelTrigger.Render(fmt.Sprintf("%d ", i+1))+style.Render(" "+label+" "))
+	}
+	labelLine := strings.Join(labels, "  ")
+	if labelLine == "" {
+		labelLine = styles.InfoLabel.Render("No labels configured")
+	}
+
+	runes := []rune(m.annotDoc.Text)
+	var position string
+	if start, end, ok := m.annotSelection(); ok {
+		position = fmt.Sprintf("Selected [%d, %d) %q", start, end, truncateValue(string(runes[start:end]), 40))
+	} else if i := m.annotDoc.At(m.annotCursor); i >= 0 {
+		s := m.annotDoc.Spans[i]
+		position = fmt.Sprintf("%s [%d, %d) %q", s.Label, s.Start, s.End, truncateValue(string(runes[s.Start:s.End]), 40))
+	} else {
+		position = fmt.Sprintf("Offset %d", m.annotCursor)
+	}
+
+	height := m.detailViewport.Height - 2
+	if height < 1 {
+		height = 1
+	}
+	text := m.renderAnnotText(m.detailViewport.Width, height)
+
+	return detailStyle.Render(lipgloss.JoinVertical(lipgloss.Left, info, labelLine, styles.Text.Render(position), "", text))
+}
+
+// renderAnnotText lays the text out in lines of at most width cells,
+// breaking after spaces where possible, and shows the part around the cursor.
+func (m *Model) renderAnnotText(width, height int) string {
+	runes := []rune(m.annotDoc.Text)
+	spanAt := make([]int, len(runes))
+	for i := range spanAt {
+		spanAt[i] = -1
+	}
+	sorted := spans.Sorted(m.annotDoc.Spans)
+	pos := 0
+	for i, s := range sorted {
+		if !s.Valid(len(runes)) || s.Start < pos {
+			continue
+		}
+		for j := s.Start; j < s.End; j++ {
+			spanAt[j] = i
+		}
+		pos = s.End
+	}
+	selStart, selEnd, selected := m.annotSelection()
+
+	var lines [][]annotCell
+	var current []annotCell
+	currentWidth := 0
+	breakAt := 0
+	push := func(cell annotCell) {
+		if width > 0 && currentWidth+cell.width > width && len(current) > 0 {
+			var carry []annotCell
+			if breakAt > 0 && breakAt < len(current) {
+				carry = append(carry, current[breakAt:]...)
+				current = current[:breakAt]
+			}
+			lines = append(lines, current)
+			current = carry
+			currentWidth = 0
+			for _, c := range current {
+				currentWidth += c.width
+			}
+			breakAt = 0
+		}
+		current = append(current, cell)
+		currentWidth += cell.width
+		if cell.space {
+			breakAt = len(current)
+		}
+	}
+
+	for i, r := range runes {
+		style := styles.Text
+		if spanAt[i] >= 0 {
+			style = lipgloss.NewStyle().Background(spanLabelColor(sorted[spanAt[i]].Label)).Foreground(lipgloss.Color("231"))
+		}
+		if selected && i >= selStart && i < selEnd {
+			style = styles.ListSelected
+		}
+		isCursor := i == m.annotCursor
+		if isCursor {
+			style = style.Reverse(true)
+		}
+
+		switch {
+		case r == '\n':
+			if isCursor {
+				push(annotCell{text: " ", width: 1, style: style, cursor: true})
+			}
+			lines = append(lines, current)
+			current, currentWidth, breakAt = nil, 0, 0
+		default:
+			if r == '\t' {
+				r = ' '
+			}
+			push(annotCell{text: string(r), width: runewidth.RuneWidth(r), style: style, cursor: isCursor, space: unicode.IsSpace(r)})
+		}
+
+		if s := spanAt[i]; s >= 0 && sorted[s].End == i+1 && sorted[s].Label != "" {
+			label := " " + sorted[s].Label
+			labelStyle := lipgloss.NewStyle().Background(spanLabelColor(sorted[s].Label)).Foreground(lipgloss.Color("231")).Bold(true)
+			push(annotCell{text: label, width: runewidth.StringWidth(label), style: labelStyle})
+		}
+	}
+	lines = append(lines, current)
+
+	cursorLine := 0
+	for i, line := range lines {
+		for _, cell := range line {
+			if cell.cursor {
+				cursorLine = i
+			}
+		}
+	}
+	if cursorLine < m.annotOffset {
+		m.annotOffset = cursorLine
+	}
+	if cursorLine >= m.annotOffset+height {
+		m.annotOffset = cursorLine - height + 1
+	}
+
+	end := m.annotOffset + height
+	if end > len(lines) {
+		end = len(lines)
+	}
+	rendered := make([]string, 0, end-m.annotOffset)
+	for _, line := range lines[m.annotOffset:end] {
+		var b strings.Builder
+		for _, cell := range line {
+			b.WriteString(cell.style.Render(cell.text))
+		}
+		rendered = append(rendered, b.String())
+	}
+	return strings.Join(rendered, "\n")
+}
@@ -0,0 +1,249 @@
+package tui
+
+import (
+	"cutl/internal/audit"
+	"cutl/internal/config"
+	"cutl/internal/dataset"
+	"cutl/internal/messages"
+	"cutl/internal/query"
+	"cutl/internal/tui/cutable"
+	"cutl/internal/tui/styles"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+)
+
+// auditRowsShown caps the rows listed per operation in the log viewer.
+const auditRowsShown = 5
+
+// auditConfig returns the change log settings of the file, or nil when
+// changes are not logged.
+func (m *Model) auditConfig() *config.AuditConfig {
+	fileConfig, _ := m.config.GetFileConfig(m.jsonlPath)
+	return fileConfig.Audit
+}
+
+// EnableAuditLog turns on the change log for the file, keeping a configured
+// key. It must be called before the program starts.
+func (m *Model) EnableAuditLog() error {
+	if m.auditConfig() != nil {
+		return nil
+	}
+	return m.config.UpdateAudit(m.jsonlPath, &config.AuditConfig{})
+}
+
+// journalingWanted reports whether the table should record its changes: for
+// the change log or a script being recorded. Merged and diff tables do not
+// correspond to one file, so nothing is recorded for them.
+func (m *Model) journalingWanted() bool {
+	return (m.auditConfig() != nil || m.recording != nil) && !m.isMerged() && !m.isDiff()
+}
+
+// updateJournaling hands the operations recorded so far to their consumers
+// before journaling is switched on or off.
+func (m *Model) updateJournaling() {
+	m.takeJournal()
+	m.table.SetJournaling(m.journalingWanted())
+}
+
+// takeJournal moves the operations recorded by the table to the change log
+// queue and to the script being recorded.
+func (m *Model) takeJournal() {
+	operations := m.table.TakeJournal()
+	if len(operations) == 0 {
+		return
+	}
+	if m.auditConfig() != nil {
+		m.pendingAudit = append(m.pendingAudit, operations...)
+	}
+	if m.recording != nil {
+		m.recordSteps(operations)
+	}
+}
+
+// flushAudit appends the operations recorded since the last write to the log
+// of path. source names the file the recorded lines refer to.
+func (m *Model) flushAudit(path, source string) {
+	m.takeJournal()
+	operations := m.pendingAudit
+	m.pendingAudit = nil
+	if len(operations) == 0 {
+		return
+	}
+	records := auditRecords(operations, source, m.auditConfig())
+	if err := audit.Append(audit.LogPath(path), records); err != nil {
+		log.Errorf("Failed to write audit log: %v", err)
+		m.setStatusErrorMessage(fmt.Sprintf("Saved, but the audit log failed: %v", err), true)
+	}
+}
+
+func auditRecords(operations []cutable.Operation, source string, auditConfig *config.AuditConfig) []audit.Record {
+	var key *query.Query
+	if auditConfig != nil && auditConfig.Key != "" {
+		compiled, err := query.Compile(auditConfig.Key)
+		if err != nil {
+			log.Warnf("Invalid audit key %q: %v", auditConfig.Key, err)
+		}
+		key = compiled
+	}
+
+	user := audit.CurrentUser()
+	records := make([]audit.Record, 0, len(operations))
+	for _, operation := range operations {
+		record := audit.Record{
+			Time:  operation.Time,
+			User:  user,
+			File:  filepath.Base(source),
+			Op:    operation.Kind,
+			Paths: operation.Paths,
+			Expr:  operation.Expr,
+		}
+		if operation.Replace != nil {
+			record.Expr = operation.Replace.String()
+		}
+		for _, change := range operation.Rows {
+			row := audit.Row{Line: change.Origin, Hash: dataset.RowHash(change.Old)}
+			if key != nil {
+				if id, ok, err := key.First(change.Old); err == nil && ok {
+					row.ID = id
+				}
+			}
+			if change.New == nil {
+				row.Old = change.Old
+			} else {
+				for _, field := range dataset.DiffFields(change.Old, change.New) {
+					row.Changes = append(row.Changes, audit.Field{Path: field.Path, Old: field.Old, New: field.New})
+				}
+			}
+			record.Rows = append(record.Rows, row)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// runAuditCommand handles `audit [on [--key EXPR]|off]`. Without arguments
+// it opens the log of the file.
+func (m *Model) runAuditCommand(args string, options map[string]string) (tea.Cmd, error) {
+	for name := range options {
+		if name != "key" || args != "on" {
+			return nil, fmt.Errorf("usage: audit [on [--key EXPR]|off]")
+		}
+	}
+
+	logPath := audit.LogPath(m.jsonlPath)
+	switch args {
+	case "":
+		m.loading = true
+		m.loadingText = "Loading audit log..."
+		return tea.Batch(m.spinner.Tick, func() tea.Msg {
+			records, err := audit.Load(logPath)
+			return messages.AuditLogLoaded{Records: records, Error: err}
+		}), nil
+	case "on":
+		auditConfig := &config.AuditConfig{Key: options["key"]}
+		if auditConfig.Key != "" {
+			if _, err := query.Compile(auditConfig.Key); err != nil {
+				return nil, fmt.Errorf("--key: %w", err)
+			}
+		}
+		m.takeJournal()
+		if err := m.config.UpdateAudit(m.jsonlPath, auditConfig); err != nil {
+			return nil, fmt.Errorf("failed to save audit settings: %w", err)
+		}
+		m.updateJournaling()
+		m.setStatusMessage(fmt.Sprintf("Changes are logged to %s when written", filepath.Base(logPath)), true)
+		return nil, nil
+	case "off":
+		m.takeJournal()
+		if err := m.config.UpdateAudit(m.jsonlPath, nil); err != nil {
+			return nil, fmt.Errorf("failed to save audit settings: %w", err)
+		}
+		m.pendingAudit = nil
+		m.updateJournaling()
+		m.setStatusMessage("Changes are no longer logged", true)
+		return nil, nil
+	}
+	return nil, fmt.Errorf("usage: audit [on [--key EXPR]|off]")
+}
+
+func (m *Model) handleAuditLogLoaded(msg messages.AuditLogLoaded) {
+	m.loading = false
+	if msg.Error != nil {
+		m.setStatusErrorMessage(fmt.Sprintf("Failed to load audit log: %v", msg.Error), true)
+		return
+	}
+	m.auditRecords = msg.Records
+	m.state = auditView
+	m.auditViewport.GotoTop()
+}
+
+// renderAuditLines lists the operations newest first, each followed by the
+// first rows it affected.
+func (m *Model) renderAuditLines() string {
+	width := m.auditViewport.Width
+	if width < 10 {
+		width = 10
+	}
+	if len(m.auditRecords) == 0 {
+		return styles.Text.Render("No changes logged yet.")
+	}
+
+	var lines []string
+	for i := len(m.auditRecords) - 1; i >= 0; i-- {
+		record := m.auditRecords[i]
+		header := fmt.Sprintf("%s  %s  %s", record.Time.Local().Format("2006-01-02 15:04:05"), record.User, record.Op)
+		if len(record.Paths) > 0 {
+			header += " " + strings.Join(record.Paths, ", ")
+		}
+		if record.Expr != "" {
+			header += " " + record.Expr
+		}
+		header += fmt.Sprintf("  %d rows  %s", len(record.Rows), record.File)
+		lines = append(lines, styles.Label.Render(truncateValue(header, width)))
+
+		for j, row := range record.Rows {
+			if j == auditRowsShown {
+				lines = append(lines, styles.InfoLabel.Render(fmt.Sprintf("  … %d more rows", len(record.Rows)-j)))
+				break
+			}
+			name := fmt.Sprintf("  line %d", row.Line)
+			if row.ID != nil {
+				name += " id " + query.Format(row.ID)
+			}
+			if record.Op == audit.OpDelete {
+				lines = append(lines, styles.NoLabel.Render(truncateValue(name+": "+diffValue(row.Old), width)))
+				continue
+			}
+			for _, field := range row.Changes {
+				text := fmt.Sprintf("%s %s: %s → %s", name, field.Path, diffValue(field.Old), diffValue(field.New))
+				lines = append(lines, styles.Text.Render(truncateValue(text, width)))
+			}
+		}
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *Model) renderAuditView() string {
+	detailStyle := styles.DetailPanel
+	innerWidth := m.width - 8
+	if innerWidth > 0 {
+		detailStyle = detailStyle.Copy().Width(innerWidth)
+	} else {
+		detailStyle = detailStyle.Copy()
+	}
+
+	// Lines are cut to the viewport, which is only sized while rendering.
+	m.auditViewport.SetContent(m.renderAuditLines())
+	text := fmt.Sprintf("Audit log %s, %d operations", filepath.Base(audit.LogPath(m.jsonlPath)), len(m.auditRecords))
+	if pending := m.table.JournalLength() + len(m.pendingAudit); pending > 0 {
+		text += fmt.Sprintf(", %d not written yet", pending)
+	}
+	info := styles.InfoLabel.Render(text + " — ↑/↓ scroll, ESC return")
+	return detailStyle.Render(lipgloss.JoinVertical(lipgloss.Left, info, "", m.auditViewport.View()))
+}
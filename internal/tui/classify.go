@@ -0,0 +1,250 @@
+package tui
+
+import (
+	"cutl/internal/config"
+	"cutl/internal/query"
+	"cutl/internal/spans"
+	"cutl/internal/tui/styles"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const defaultClassifyPath = ".label"
+
+// classifyState is the running labeling loop: its settings and the label
+// path compiled once, since every row is checked on each label.
+type classifyState struct {
+	config.ClassifyConfig
+	label *query.Query
+}
+
+func (m *Model) classifyConfig() config.ClassifyConfig {
+	fileConfig, _ := m.config.GetFileConfig(m.jsonlPath)
+	if fileConfig.Classify == nil {
+		return config.ClassifyConfig{Path: defaultClassifyPath}
+	}
+	return *fileConfig.Classify
+}
+
+// runClassifyCommand starts the labeling loop, e.g.
+// `:classify --labels positive,negative,neutral --path .sentiment`. The
+// label set and path are remembered per file, so later a bare `:classify`
+// continues where the last session stopped.
+func (m *Model) runClassifyCommand(args string, options map[string]string) (tea.Cmd, error) {
+	if args != "" {
+		return nil, fmt.Errorf("usage: classify [--labels A,B,...] [--path .label] [--text .text]")
+	}
+
+	classify := m.classifyConfig()
+	for name, value := range options {
+		switch name {
+		case "path", "text":
+			if !strings.HasPrefix(value, ".") {
+				return nil, fmt.Errorf("--%s must be a path like .label", name)
+			}
+			if name == "path" {
+				classify.Path = value
+			} else {
+				classify.Text = value
+			}
+		case "labels":
+			classify.Labels = nil
+			for _, label := range strings.Split(value, ",") {
+				if label = strings.TrimSpace(label); label != "" {
+					classify.Labels = append(classify.Labels, label)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown option --%s", name)
+		}
+	}
+
+	if !writablePath.MatchString(classify.Path) {
+		return nil, fmt.Errorf("--path must be a field path like .label or .meta.label, labels are written to it")
+	}
+	if len(classify.Labels) == 0 {
+		return nil, fmt.Errorf("no labels configured (use --labels A,B,...)")
+	}
+	if len(classify.Labels) > 9 {
+		return nil, fmt.Errorf("at most 9 labels can be assigned to keys 1-9")
+	}
+	if len(options) > 0 {
+		if err := m.config.UpdateClassify(m.jsonlPath, &classify); err != nil {
+			return nil, fmt.Errorf("failed to save labeling settings: %w", err)
+		}
+	}
+	if m.table.FilteredRows() == 0 {
+		return nil, fmt.Errorf("no entries to label")
+	}
+
+	label, err := query.Compile(classify.Path)
+	if err != nil {
+		return nil, fmt.Errorf("--path: %w", err)
+	}
+	m.classify = classifyState{ClassifyConfig: classify, label: label}
+	m.state = classifyView
+	if entry := m.table.SelectedEntry(); entry != nil && m.isLabeled(entry.Data) {
+		m.advanceToUnlabeled(m.filteredLines(), entry.Line)
+	}
+	m.updateClassifyProgress()
+	return nil, nil
+}
+
+func (m *Model) isLabeled(data any) bool {
+	value, ok, err := m.classify.label.First(data)
+	if err != nil || !ok || value == nil {
+		return false
+	}
+	return value != ""
+}
+
+// assignClassLabel writes the label on key index+1 to the selected entry and
+// moves on to the next unlabeled one.
+func (m *Model) assignClassLabel(index int) error {
+	if index >= len(m.classify.Labels) {
+		return fmt.Errorf("no label on key %d", index+1)
+	}
+	return m.setClassLabel(m.classify.Labels[index], true)
+}
+
+func (m *Model) setClassLabel(label string, advance bool) error {
+	entry := m.table.SelectedEntry()
+	if entry == nil {
+		return fmt.Errorf("no entry selected")
+	}
+	line := entry.Line
+	// Remember the order before updating, the entry may leave the filter.
+	lines := m.filteredLines()
+
+	// Labels are always strings, even ones like "1" or "true"; clearing
+	// stores null.
+	var value any
+	if label != "" {
+		value = label
+	}
+	if err := m.table.SetEntryValue(line, m.classify.Path, value); err != nil {
+		return err
+	}
+	if advance {
+		m.advanceToUnlabeled(lines, line)
+	} else {
+		m.table.SelectLine(line)
+	}
+	m.updateClassifyProgress()
+	return nil
+}
+
+// advanceToUnlabeled selects the first unlabeled entry after line in the
+// given order, wrapping around. Entries no longer matching the filter are
+// skipped.
+func (m *Model) advanceToUnlabeled(lines []int, line int) {
+	start := 0
+	for i, l := range lines {
+		if l == line {
+			start = i + 1
+			break
+		}
+	}
+
+	entries := make(map[int]any)
+	for _, entry := range m.table.FilteredEntries() {
+		entries[entry.Line] = entry.Data
+	}
+	for i := 0; i < len(lines); i++ {
+		candidate := lines[(start+i)%len(lines)]
+		data, ok := entries[candidate]
+		if ok && !m.isLabeled(data) {
+			m.table.SelectLine(candidate)
+			return
+		}
+	}
+
+	if !m.table.SelectLine(line) {
+		m.table.SelectLine(lines[len(lines)-1])
+	}
+	m.setStatusMessage("All filtered entries are labeled", true)
+}
+
+func (m *Model) updateClassifyProgress() {
+	labeled := 0
+	entries := m.table.FilteredEntries()
+	for _, entry := range entries {
+		if m.isLabeled(entry.Data) {
+			labeled++
+		}
+	}
+	m.commandPanel.SetProgress(fmt.Sprintf("%d labeled · %d remaining", labeled, len(entries)-labeled))
+}
+
+func (m *Model) closeClassify() {
+	m.state = tableView
+	m.commandPanel.SetProgress("")
+}
+
+func (m *Model) renderClassifyView(height int) string {
+	detailStyle := styles.DetailPanel
+	innerWidth := m.width - 8
+	if innerWidth > 0 {
+		detailStyle = detailStyle.Copy().Width(innerWidth)
+	} else {
+		detailStyle = detailStyle.Copy()
+	}
+
+	entry := m.table.SelectedEntry()
+	if entry == nil {
+		return detailStyle.Render(styles.Text.Render("No entry selected."))
+	}
+
+	info := styles.InfoLabel.Render(fmt.Sprintf(
+		"Labeling %s of line %d — 1-9 label, 0 clear, J/K next/previous, TAB next unlabeled, ESC return",
+		m.classify.Path, entry.Line,
+	))
+
+	keys := make([]string, 0, len(m.classify.Labels))
+	for i, label := range m.classify.Labels {
+		keys = append(keys, lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			styles.CommandLabelTrigger.Render(fmt.Sprintf("%d ", i+1)),
+			styles.CommandLabel.Render(label),
+		))
+	}
+
+	current := styles.InfoLabel.Render("unlabeled")
+	if value, ok, err := m.classify.label.First(entry.Data); err == nil && ok && value != nil && value != "" {
+		current = styles.OkLabel.Render(query.Format(value))
+	}
+
+	textPath := m.classify.Text
+	if textPath == "" {
+		textPath = spans.DefaultTextPath
+	}
+	text := ""
+	if q, err := query.Compile(textPath); err == nil {
+		if value, ok, err := q.First(entry.Data); err == nil && ok {
+			text = query.Format(value)
+		}
+	}
+
+	textWidth := innerWidth - 8
+	if textWidth < 20 {
+		textWidth = 20
+	}
+	textStyle := styles.Text.Copy().Bold(true).Padding(1, 4).Width(textWidth)
+	lines := strings.Split(textStyle.Render(text), "\n")
+	// Keep room for the header lines above the text.
+	if maxLines := height - 6; maxLines > 0 && len(lines) > maxLines {
+		lines = append(lines[:maxLines-1], styles.InfoLabel.Render("    …"))
+	}
+
+	return detailStyle.Render(lipgloss.JoinVertical(
+		lipgloss.Left,
+		info,
+		"",
+		lipgloss.JoinHorizontal(lipgloss.Top, styles.Label.Render("Label: "), current),
+		strings.Join(lines, "\n"),
+		strings.Join(keys, ""),
+	))
+}
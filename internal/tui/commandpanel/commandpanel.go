@@ -17,6 +17,10 @@ const (
 	modeColumns inputMode = iota
 	modeFilter
 	modePrompt
+	modeHighlight
+	modeGroupBy
+	modeCommand
+	modeNote
 )
 
 type Model struct {
@@ -28,6 +32,7 @@ type Model struct {
 	currentLine     int
 	filterActive    bool
 	markedCount     int
+	progress        string
 	statusMessage   string
 	isStatusError   bool
 	isStatusNeutral bool
@@ -82,6 +87,22 @@ func (m *Model) ActivateFilter(filter string) {
 	m.activateWithMode(modeFilter, filter, "jq filter, e.g. .field == \"value\"", 200)
 }
 
+func (m *Model) ActivateHighlights(rules string) {
+	m.activateWithMode(modeHighlight, rules, ".label == null => red; .score < 0.5 => yellow @.score", 600)
+}
+
+func (m *Model) ActivateGroupBy(value string) {
+	m.activateWithMode(modeGroupBy, value, ".source => avg(.score), max(.score)", 400)
+}
+
+func (m *Model) ActivateCommand() {
+	m.activateWithMode(modeCommand, "", "dedupe .text --near 0.8", 400)
+}
+
+func (m *Model) ActivateNote(note string) {
+	m.activateWithMode(modeNote, note, "Why is this row flagged?", 400)
+}
+
 func (m *Model) ActivatePrompt(initial string) {
 	if !m.aiEnabled {
 		return
@@ -124,6 +145,12 @@ func (m *Model) SetMeta(totalRows, filteredRows, filteredPosition, markedCount i
 	m.filterActive = filterActive
 }
 
+// SetProgress shows a progress note, e.g. of the labeling loop, next to
+// the row counter. An empty string hides it.
+func (m *Model) SetProgress(progress string) {
+	m.progress = progress
+}
+
 func (m *Model) SetStatus(message string) {
 	m.statusMessage = message
 	m.isStatusError = false
@@ -188,6 +215,10 @@ func (m *Model) View() string {
 	sections = append(sections, lipgloss.StyleRunes("F Filter", []int{0, 0}, styles.CommandLabelTrigger, styles.CommandLabel))
 	sections = append(sections, lipgloss.StyleRunes("C Columns", []int{0, 0}, styles.CommandLabelTrigger, styles.CommandLabel))
 	sections = append(sections, lipgloss.StyleRunes("E Edit", []int{0, 0}, styles.CommandLabelTrigger, styles.CommandLabel))
+	sections = append(sections, lipgloss.StyleRunes("H Highlight", []int{0, 0}, styles.CommandLabelTrigger, styles.CommandLabel))
+	sections = append(sections, lipgloss.StyleRunes("S Stats", []int{0, 0}, styles.CommandLabelTrigger, styles.CommandLabel))
+	sections = append(sections, lipgloss.StyleRunes("T Tally", []int{0, 0}, styles.CommandLabelTrigger, styles.CommandLabel))
+	sections = append(sections, lipgloss.StyleRunes("A Aggregate", []int{0, 0}, styles.CommandLabelTrigger, styles.CommandLabel))
 	sections = append(sections, lipgloss.JoinHorizontal(
 		lipgloss.Top,
 		styles.CommandLabelTrigger.Render("SPACE "),
@@ -210,6 +241,11 @@ func (m *Model) View() string {
 		styles.CommandLabelTrigger.Render("Ctrl+A "),
 		styles.CommandLabel.Render("Select all"),
 	))
+	sections = append(sections, lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		styles.CommandLabelTrigger.Render("Ctrl+V "),
+		styles.CommandLabel.Render("Mark range"),
+	))
 	sections = append(sections, lipgloss.JoinHorizontal(
 		lipgloss.Top,
 		styles.CommandLabelTrigger.Render("X "),
@@ -225,6 +261,11 @@ func (m *Model) View() string {
 		styles.CommandLabelTrigger.Render("1-9 "),
 		styles.CommandLabel.Render("Sort by column"),
 	))
+	sections = append(sections, lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		styles.CommandLabelTrigger.Render(": "),
+		styles.CommandLabel.Render("Command"),
+	))
 	sections = append(sections, lipgloss.JoinHorizontal(
 		lipgloss.Top,
 		styles.CommandLabelTrigger.Render("V "),
@@ -250,6 +291,9 @@ func (m *Model) metaContent() string {
 	}
 
 	base := fmt.Sprintf("%s / %d (%d total)", current, total, m.totalRows)
+	if m.progress != "" {
+		base = m.progress + " · " + base
+	}
 
 	return base
 }
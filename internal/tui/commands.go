@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"cutl/internal/tui/cutable"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commandHandler runs one `:` command. args is everything after the command
+// name, options holds trailing `--name value` pairs.
+type commandHandler func(m *Model, args string, options map[string]string) (tea.Cmd, error)
+
+var commandHandlers = map[string]commandHandler{
+	"audit":     (*Model).runAuditCommand,
+	"classify":  (*Model).runClassifyCommand,
+	"conflicts": (*Model).runConflictsCommand,
+	"dedupe":    (*Model).runDedupeCommand,
+	"diff":      (*Model).runDiffCommand,
+	"export":    (*Model).runExportCommand,
+	"infer":     (*Model).runInferCommand,
+	"invalid":   (*Model).runInvalidCommand,
+	"mark":      (*Model).runMarkCommand,
+	"record":    (*Model).runRecordCommand,
+	"replace":   (*Model).runReplaceCommand,
+	"review":    (*Model).runReviewCommand,
+	"sample":    (*Model).runSampleCommand,
+	"saveas":    (*Model).runSaveAsCommand,
+	"spans":     (*Model).runSpansCommand,
+	"split":     (*Model).runSplitCommand,
+	"tag":       (*Model).runTagCommand,
+	"tags":      (*Model).runTagsCommand,
+	"transform": (*Model).runTransformCommand,
+	"unmark":    (*Model).runUnmarkCommand,
+}
+
+// runCommand parses and dispatches a line entered in the command input.
+func (m *Model) runCommand(line string) (tea.Cmd, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil, nil
+	}
+
+	name, rest, _ := strings.Cut(line, " ")
+	handler, ok := commandHandlers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown command %q (available: %s)", name, strings.Join(commandNames(), ", "))
+	}
+
+	args, options := parseCommandOptions(rest)
+	return handler(m, args, options)
+}
+
+func commandNames() []string {
+	names := make([]string, 0, len(commandHandlers))
+	for name := range commandHandlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseCommandOptions splits `EXPR --flag --name value` into the leading
+// argument and its options. `--` inside jq strings or groups is left alone.
+func parseCommandOptions(text string) (string, map[string]string) {
+	parts := cutable.SplitTopLevel(text, "--")
+	options := make(map[string]string)
+	for _, part := range parts[1:] {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), " ")
+		if name == "" {
+			continue
+		}
+		options[name] = strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(parts[0]), options
+}
@@ -1,11 +1,15 @@
 package cutable
 
 import (
+	"cutl/internal/audit"
+	"cutl/internal/config"
 	"cutl/internal/editor"
 	"cutl/internal/messages"
+	"cutl/internal/query"
+	"cutl/internal/review"
+	"cutl/internal/schema"
 	"encoding/json"
 	"fmt"
-	"math"
 	"sort"
 	"strconv"
 	"strings"
@@ -28,9 +32,25 @@ type Model struct {
 	filteredEntries   []editor.Entry
 	marked            map[int]struct{}
 	sortColumn        int
+	columnCursor      int
 	sortAscending     bool
 	columnWidths      []int
 	columnWidthsDirty bool
+	styles            table.Styles
+	rules             []config.HighlightRule
+	highlightRules    []highlightRule
+	tableHeight       int
+	viewStart         int
+	validator         *schema.Validator
+	violations        map[int][]schema.Violation
+	reviews           map[int]review.Status
+	tagDefs           []config.Tag
+	tags              map[int]map[string]struct{}
+	visualAnchor      int
+	sample            map[int]struct{}
+	origins           map[int]int
+	journaling        bool
+	journal           []Operation
 }
 
 const (
@@ -45,10 +65,12 @@ func New() Model {
 	t := table.New(
 		table.WithFocused(true),
 	)
-	t.SetStyles(defaultStyles())
+	styles := DefaultStyles()
+	t.SetStyles(styles)
 
 	m := Model{
 		table:             t,
+		styles:            styles,
 		columnQueries:     []string{}, // Initialisiere leeres Array
 		marked:            make(map[int]struct{}),
 		sortColumn:        -1,
@@ -82,14 +104,18 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			m.sortAscending = true
 		}
 		m.rebuildTableWithSort()
+	case messages.HighlightRulesChanged:
+		m.SetHighlightRules(msg.Rules)
 	case messages.InputFileLoaded:
 		log.Debugf("Received InputFileLoaded message with %d entries.", len(msg.Content))
 		m.rawEntries = msg.Content
+		m.origins = nil
+		m.journal = nil
 
 		// Only discover columns if none are set (they might be loaded from config)
 		if len(m.columnQueries) == 0 && len(m.rawEntries) > 0 {
 			if first, ok := m.rawEntries[0].Data.(map[string]interface{}); ok {
-				m.columnQueries = discoverInitialColumnQueries(first)
+				m.columnQueries = DiscoverColumnQueries(first)
 				m.columnWidthsDirty = true
 				log.Debugf("Auto-discovered columns: %v", m.columnQueries)
 			}
@@ -133,35 +159,20 @@ func (m *Model) rebuildTableInternalWithErrorCheck(preserveSelection bool) error
 		}
 	}
 
-	showMarker := len(m.marked) > 0
+	showMarker := m.showMarkerColumn()
 
 	columns := make([]table.Column, 0, len(m.columnQueries)+1)
 	if showMarker {
 		columns = append(columns, table.Column{Title: "●", Width: 2})
 	}
-	for i, q := range m.columnQueries {
-		title := q
-		if m.sortColumn == i {
-			if m.sortAscending {
-				title = q + " ↑"
-			} else {
-				title = q + " ↓"
-			}
-		}
-		columns = append(columns, table.Column{Title: title, Width: 10})
+	for i := range m.columnQueries {
+		columns = append(columns, table.Column{Title: m.columnTitle(i), Width: 10})
 	}
 
 	m.filteredEntries = m.rawEntries
 	if m.filterQuery != "" {
-		// Check if this is a special "marked only" filter
-		if m.isMarkedOnlyFilter(m.filterQuery) {
-			// Apply marked-only filter directly
-			var filtered []editor.Entry
-			for _, entry := range m.rawEntries {
-				if _, isMarked := m.marked[entry.Line]; isMarked {
-					filtered = append(filtered, entry)
-				}
-			}
+		// Check if this is a special "marked only" or "invalid only" filter
+		if filtered, ok := m.applySpecialFilter(m.filterQuery); ok {
 			m.filteredEntries = filtered
 		} else {
 			// Apply regular jq filter
@@ -271,35 +282,20 @@ func (m *Model) rebuildTableInternal(preserveSelection bool) {
 		}
 	}
 
-	showMarker := len(m.marked) > 0
+	showMarker := m.showMarkerColumn()
 
 	columns := make([]table.Column, 0, len(m.columnQueries)+1)
 	if showMarker {
 		columns = append(columns, table.Column{Title: "●", Width: 2})
 	}
-	for i, q := range m.columnQueries {
-		title := q
-		if m.sortColumn == i {
-			if m.sortAscending {
-				title = q + " ↑"
-			} else {
-				title = q + " ↓"
-			}
-		}
-		columns = append(columns, table.Column{Title: title, Width: 10})
+	for i := range m.columnQueries {
+		columns = append(columns, table.Column{Title: m.columnTitle(i), Width: 10})
 	}
 
 	m.filteredEntries = m.rawEntries
 	if m.filterQuery != "" {
-		// Check if this is a special "marked only" filter
-		if m.isMarkedOnlyFilter(m.filterQuery) {
-			// Apply marked-only filter directly
-			var filtered []editor.Entry
-			for _, entry := range m.rawEntries {
-				if _, isMarked := m.marked[entry.Line]; isMarked {
-					filtered = append(filtered, entry)
-				}
-			}
+		// Check if this is a special "marked only" or "invalid only" filter
+		if filtered, ok := m.applySpecialFilter(m.filterQuery); ok {
 			m.filteredEntries = filtered
 		} else {
 			// Apply regular jq filter
@@ -409,14 +405,86 @@ func (m *Model) rebuildTableInternal(preserveSelection bool) {
 	m.columnWidthsDirty = false
 }
 
+// showMarkerColumn reports whether any row carries a mark, a violation, a
+// review status or a tag.
+func (m *Model) showMarkerColumn() bool {
+	return len(m.marked) > 0 || len(m.violations) > 0 || len(m.reviews) > 0 || len(m.tags) > 0
+}
+
+// markerWidth is the width of the widest marker cell among the filtered
+// rows plus a gap, at least 2.
+func (m *Model) markerWidth() int {
+	width := 1
+	for _, entry := range m.filteredEntries {
+		if w := lipgloss.Width(m.markerSymbol(entry.Line)); w > width {
+			width = w
+		}
+	}
+	return width + 1
+}
+
 func (m *Model) markerSymbol(line int) string {
+	symbol := ""
 	if _, ok := m.marked[line]; ok {
-		return "●"
+		symbol = "●"
+	}
+	if _, invalid := m.violations[line]; invalid {
+		symbol += "✗"
 	}
-	return ""
+	if status, ok := m.reviews[line]; ok {
+		symbol += reviewSymbols[status.State]
+	}
+	return symbol + m.tagSymbols(line)
 }
 
-func defaultStyles() table.Styles {
+// applySpecialFilter handles the internal filters that select entries by
+// state instead of by content. ok is false for regular jq filters.
+func (m *Model) applySpecialFilter(filter string) ([]editor.Entry, bool) {
+	var keep func(line int) bool
+	switch {
+	case m.isMarkedOnlyFilter(filter):
+		keep = func(line int) bool {
+			_, isMarked := m.marked[line]
+			return isMarked
+		}
+	case m.isInvalidOnlyFilter(filter):
+		keep = func(line int) bool {
+			_, invalid := m.violations[line]
+			return invalid
+		}
+	case m.isSampleFilter(filter):
+		keep = func(line int) bool {
+			_, sampled := m.sample[line]
+			return sampled
+		}
+	default:
+		if name, ok := m.tagFilterName(filter); ok {
+			keep = func(line int) bool {
+				_, tagged := m.tags[line][name]
+				return tagged
+			}
+			break
+		}
+		state, ok := m.reviewFilterState(filter)
+		if !ok {
+			return nil, false
+		}
+		keep = func(line int) bool {
+			return m.Review(line).State == state
+		}
+	}
+
+	var filtered []editor.Entry
+	for _, entry := range m.rawEntries {
+		if keep(entry.Line) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered, true
+}
+
+// DefaultStyles are the table styles shared by every table cutl renders.
+func DefaultStyles() table.Styles {
 	s := table.DefaultStyles()
 	s.Header = s.Header.
 		BorderStyle(lipgloss.NormalBorder()).
@@ -431,13 +499,81 @@ func defaultStyles() table.Styles {
 }
 
 func (m *Model) View() string {
-	return m.table.View()
+	return m.renderTable()
 }
 
 func (m *Model) ColumnQueries() []string {
 	return m.columnQueries
 }
 
+func (m *Model) SortColumn() int {
+	return m.sortColumn
+}
+
+// ColumnCursor is the column the cursor is in, moved with MoveColumnCursor.
+func (m *Model) ColumnCursor() int {
+	if m.columnCursor >= len(m.columnQueries) {
+		return max(len(m.columnQueries)-1, 0)
+	}
+	return m.columnCursor
+}
+
+// MoveColumnCursor moves the column cursor by delta columns, stopping at the
+// first and last one.
+func (m *Model) MoveColumnCursor(delta int) {
+	cursor := min(max(m.ColumnCursor()+delta, 0), max(len(m.columnQueries)-1, 0))
+	if cursor == m.columnCursor {
+		return
+	}
+	m.columnCursor = cursor
+	columns := m.table.Columns()
+	offset := len(columns) - len(m.columnQueries)
+	if offset < 0 {
+		return
+	}
+	for i := range m.columnQueries {
+		columns[offset+i].Title = m.columnTitle(i)
+	}
+	m.table.SetColumns(columns)
+	m.columnWidthsDirty = true
+}
+
+// columnTitle is the header of a column: its query, the sort direction and
+// a ▸ in front when the column cursor is in it.
+func (m *Model) columnTitle(i int) string {
+	title := m.columnQueries[i]
+	if m.sortColumn == i {
+		if m.sortAscending {
+			title += " ↑"
+		} else {
+			title += " ↓"
+		}
+	}
+	if i == m.ColumnCursor() {
+		title = "▸ " + title
+	}
+	return title
+}
+
+// SortState returns the sort column (-1 when unsorted) and direction.
+func (m *Model) SortState() (int, bool) {
+	return m.sortColumn, m.sortAscending
+}
+
+// RestoreView applies a saved filter and sort at once, without the toggling
+// SortByColumn does. A filter that no longer works is dropped.
+func (m *Model) RestoreView(filter string, sortColumn int, ascending bool) error {
+	m.filterQuery = filter
+	m.sortColumn = sortColumn
+	m.sortAscending = ascending
+	if err := m.rebuildTableInternalWithErrorCheck(false); err != nil {
+		m.filterQuery = ""
+		m.rebuildTableInternal(false)
+		return err
+	}
+	return nil
+}
+
 func (m *Model) FilterQuery() string {
 	return m.filterQuery
 }
@@ -469,7 +605,7 @@ func (m *Model) GenerateMarkedOnlyFilter() string {
 	}
 
 	// Store the current filter as original filter before applying marked-only
-	if !m.isMarkedOnlyFilter(m.filterQuery) {
+	if !m.isSpecialFilter(m.filterQuery) {
 		m.originalFilter = m.filterQuery
 	}
 
@@ -481,6 +617,16 @@ func (m *Model) isMarkedOnlyFilter(filter string) bool {
 	return filter == "__MARKED_ONLY__"
 }
 
+func (m *Model) isSpecialFilter(filter string) bool {
+	if _, ok := m.reviewFilterState(filter); ok {
+		return true
+	}
+	if _, ok := m.tagFilterName(filter); ok {
+		return true
+	}
+	return m.isMarkedOnlyFilter(filter) || m.isInvalidOnlyFilter(filter) || m.isSampleFilter(filter)
+}
+
 func (m *Model) IsCurrentFilterMarkedOnly() bool {
 	return m.isMarkedOnlyFilter(m.filterQuery)
 }
@@ -516,6 +662,25 @@ func (m *Model) MarkAllVisible() int {
 	return markedCount
 }
 
+// MarkLines adds the given lines to the marks and returns how many of them
+// were not marked before.
+func (m *Model) MarkLines(lines []int) int {
+	if m.marked == nil {
+		m.marked = make(map[int]struct{})
+	}
+
+	markedCount := 0
+	for _, line := range lines {
+		if _, exists := m.marked[line]; !exists {
+			m.marked[line] = struct{}{}
+			markedCount++
+		}
+	}
+
+	m.rebuildTable()
+	return markedCount
+}
+
 func (m *Model) SelectedOriginalLine() int {
 	if len(m.filteredEntries) == 0 {
 		return 0
@@ -555,6 +720,18 @@ func (m *Model) SelectedEntry() *editor.Entry {
 	return &m.filteredEntries[cursor]
 }
 
+// SelectLine moves the cursor to the entry with the given line, if it is
+// part of the filtered entries.
+func (m *Model) SelectLine(line int) bool {
+	for i, entry := range m.filteredEntries {
+		if entry.Line == line {
+			m.table.SetCursor(i)
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Model) FirstEntry() *editor.Entry {
 	if len(m.filteredEntries) > 0 {
 		return &m.filteredEntries[0]
@@ -624,24 +801,30 @@ func (m *Model) DeleteMarkedOrSelected() int {
 		return 0
 	}
 
-	linesToDelete := make(map[int]struct{})
 	if len(m.marked) > 0 {
-		for line := range m.marked {
-			linesToDelete[line] = struct{}{}
-		}
-	} else {
-		selected := m.SelectedEntry()
-		if selected == nil {
-			return 0
-		}
-		linesToDelete[selected.Line] = struct{}{}
+		return m.DeleteLines(m.MarkedLines())
+	}
+	selected := m.SelectedEntry()
+	if selected == nil {
+		return 0
+	}
+	return m.DeleteLines([]int{selected.Line})
+}
+
+// DeleteLines removes the given entries and renumbers the remaining ones.
+// Marks, violations, reviews and tags follow their entries.
+func (m *Model) DeleteLines(lines []int) int {
+	linesToDelete := make(map[int]struct{}, len(lines))
+	for _, line := range lines {
+		linesToDelete[line] = struct{}{}
 	}
 
 	if len(linesToDelete) == 0 {
 		return 0
 	}
+	m.journalDelete(linesToDelete)
 
-	newEntries := make([]editor.Entry, 0, len(m.rawEntries)-len(linesToDelete))
+	newEntries := make([]editor.Entry, 0, len(m.rawEntries))
 	for _, entry := range m.rawEntries {
 		if _, remove := linesToDelete[entry.Line]; remove {
 			continue
@@ -649,17 +832,32 @@ func (m *Model) DeleteMarkedOrSelected() int {
 		newEntries = append(newEntries, entry)
 	}
 
-	if len(newEntries) == len(m.rawEntries) {
+	removed := len(m.rawEntries) - len(newEntries)
+	if removed == 0 {
 		return 0
 	}
 
+	renumbered := make(map[int]int, len(newEntries))
 	for idx := range newEntries {
+		renumbered[newEntries[idx].Line] = idx + 1
 		newEntries[idx].Line = idx + 1
 	}
+	m.remapViolations(renumbered)
+	m.remapReviews(renumbered)
+	m.remapTags(renumbered)
+	m.remapSample(renumbered)
+	m.remapOrigins(renumbered)
+
+	marked := make(map[int]struct{})
+	for line := range m.marked {
+		if newLine, ok := renumbered[line]; ok {
+			marked[newLine] = struct{}{}
+		}
+	}
 
 	previousCursor := m.table.Cursor()
 	m.rawEntries = newEntries
-	m.marked = make(map[int]struct{})
+	m.marked = marked
 	m.rebuildTable()
 
 	if len(m.filteredEntries) == 0 {
@@ -675,7 +873,7 @@ func (m *Model) DeleteMarkedOrSelected() int {
 		m.table.SetCursor(newCursor)
 	}
 
-	return len(linesToDelete)
+	return removed
 }
 
 func (m *Model) Entries() []editor.Entry {
@@ -684,7 +882,14 @@ func (m *Model) Entries() []editor.Entry {
 	return entries
 }
 
+func (m *Model) FilteredEntries() []editor.Entry {
+	entries := make([]editor.Entry, len(m.filteredEntries))
+	copy(entries, m.filteredEntries)
+	return entries
+}
+
 func (m *Model) SetHeight(height int) {
+	m.tableHeight = height
 	m.table.SetHeight(height)
 }
 
@@ -700,7 +905,17 @@ func (m *Model) updateColumnWidths(recalculate bool) {
 		return
 	}
 
-	if !recalculate && len(m.columnWidths) == numColumns {
+	// The marker column is as wide as its widest cell, so a mark, a
+	// violation, a review status and several tags all fit; the other
+	// columns share the rest.
+	startCol := 0
+	markerWidth := 0
+	if columns[0].Title == "●" {
+		startCol = 1
+		markerWidth = m.markerWidth()
+	}
+
+	if !recalculate && len(m.columnWidths) == numColumns && (startCol == 0 || m.columnWidths[0] == markerWidth) {
 		for i := range columns {
 			columns[i].Width = m.columnWidths[i]
 		}
@@ -709,7 +924,7 @@ func (m *Model) updateColumnWidths(recalculate bool) {
 	}
 
 	idealWidths := make([]int, numColumns)
-	for colIdx := range columns {
+	for colIdx := startCol; colIdx < numColumns; colIdx++ {
 		width := lipgloss.Width(columns[colIdx].Title)
 		for _, row := range rows {
 			if colIdx < len(row) {
@@ -722,12 +937,16 @@ func (m *Model) updateColumnWidths(recalculate bool) {
 		idealWidths[colIdx] = width
 	}
 
-	available := m.width - paddingWidthOffset
+	available := m.width - paddingWidthOffset - markerWidth
 	if available < 0 {
 		available = 0
 	}
 
 	widths := make([]int, numColumns)
+	if startCol == 1 {
+		widths[0] = markerWidth
+	}
+	sharing := numColumns - startCol
 	totalIdeal := 0
 	for _, w := range idealWidths {
 		totalIdeal += w
@@ -736,11 +955,11 @@ func (m *Model) updateColumnWidths(recalculate bool) {
 	if totalIdeal == 0 {
 		per := 0
 		remainder := 0
-		if numColumns > 0 {
-			per = available / numColumns
-			remainder = available % numColumns
+		if sharing > 0 {
+			per = available / sharing
+			remainder = available % sharing
 		}
-		for i := 0; i < numColumns; i++ {
+		for i := startCol; i < numColumns; i++ {
 			widths[i] = per
 			if remainder > 0 {
 				widths[i]++
@@ -749,11 +968,11 @@ func (m *Model) updateColumnWidths(recalculate bool) {
 		}
 	} else {
 		remainder := available
-		for i := 0; i < numColumns; i++ {
+		for i := startCol; i < numColumns; i++ {
 			widths[i] = (idealWidths[i] * available) / totalIdeal
 			remainder -= widths[i]
 		}
-		for i := 0; remainder > 0 && i < numColumns; i++ {
+		for i := startCol; remainder > 0 && i < numColumns; i++ {
 			widths[i]++
 			remainder--
 		}
@@ -762,10 +981,6 @@ func (m *Model) updateColumnWidths(recalculate bool) {
 	// Ensure minimum width of 10 characters for each column (except marker column)
 	const minWidth = 10
 	totalNeeded := 0
-	startCol := 0
-	if len(columns) > 0 && columns[0].Title == "●" {
-		startCol = 1 // Skip the marker column
-	}
 
 	for i := startCol; i < numColumns; i++ {
 		if widths[i] < minWidth {
@@ -798,20 +1013,6 @@ func (m *Model) updateColumnWidths(recalculate bool) {
 		}
 	}
 
-	if len(columns) > 0 && columns[0].Title == "●" && len(widths) > 1 {
-		maxIdx := 1
-		for i := 2; i < len(widths); i++ {
-			if widths[i] > widths[maxIdx] {
-				maxIdx = i
-			}
-		}
-		if widths[maxIdx] >= 2 {
-			widths[maxIdx] -= 2
-		} else {
-			widths[maxIdx] = 0
-		}
-	}
-
 	for idx := range columns {
 		columns[idx].Width = widths[idx]
 	}
@@ -821,7 +1022,9 @@ func (m *Model) updateColumnWidths(recalculate bool) {
 	m.table.SetColumns(columns)
 }
 
-func discoverInitialColumnQueries(first map[string]interface{}) []string {
+// DiscoverColumnQueries picks the initial columns from the keys of the first
+// entry, id, title and text first.
+func DiscoverColumnQueries(first map[string]interface{}) []string {
 	var keys []string
 	for k := range first {
 		if len(k) > 0 && k[0] != '_' {
@@ -932,6 +1135,11 @@ func parseNumber(s string) (float64, error) {
 
 func (m *Model) UpdateEntries(targetLines []int, values map[string]string, singleMode bool) error {
 	updatedCount := 0
+	change := m.beginChange(audit.OpEdit, targetLines)
+	if change != nil {
+		change.op.Paths = editPaths(values, singleMode)
+	}
+	defer m.finishChange(change)
 
 	if singleMode && len(targetLines) == 1 {
 		// Update single entry
@@ -974,6 +1182,38 @@ func (m *Model) UpdateEntries(targetLines []int, values map[string]string, singl
 	return nil
 }
 
+// SetEntryValue sets one path of an entry to a value as is, without the
+// type guessing of UpdateEntries, so a label "1" stays a string.
+func (m *Model) SetEntryValue(line int, path string, value any) error {
+	change := m.beginChange(audit.OpEdit, []int{line})
+	if change != nil {
+		change.op.Paths = []string{path}
+	}
+	defer m.finishChange(change)
+
+	for i := range m.rawEntries {
+		if m.rawEntries[i].Line != line {
+			continue
+		}
+		dataMap, ok := m.rawEntries[i].Data.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("entry data is not a map")
+		}
+		data := make(map[string]interface{}, len(dataMap))
+		for k, v := range dataMap {
+			data[k] = v
+		}
+		if err := setPathValue(data, path, value); err != nil {
+			return err
+		}
+		m.rawEntries[i].Data = data
+		m.revalidate(&m.rawEntries[i])
+		break
+	}
+	m.rebuildTable()
+	return nil
+}
+
 func (m *Model) updateEntryData(entry *editor.Entry, values map[string]string) error {
 	// entry.Data is of type any, so we need to cast it to map[string]interface{}
 	dataMap, ok := entry.Data.(map[string]interface{})
@@ -998,11 +1238,18 @@ func (m *Model) updateEntryData(entry *editor.Entry, values map[string]string) e
 	}
 
 	entry.Data = data
+	m.revalidate(entry)
 	log.Debugf("updateEntryData: Successfully updated entry line %d", entry.Line)
 	return nil
 }
 
 func (m *Model) setValueAtPath(data map[string]interface{}, path, value string) error {
+	return setPathValue(data, path, m.parseValue(value))
+}
+
+// setPathValue sets a dotted path such as .meta.source, creating missing
+// objects on the way.
+func setPathValue(data map[string]interface{}, path string, value any) error {
 	// Simple implementation for basic JSON paths
 	// This handles simple property access like ".name", ".age", etc.
 
@@ -1030,37 +1277,35 @@ func (m *Model) setValueAtPath(data map[string]interface{}, path, value string)
 	}
 
 	finalKey := parts[len(parts)-1]
+	current[finalKey] = value
+	return nil
+}
 
+// parseValue reads a value typed into the edit view.
+func (m *Model) parseValue(value string) any {
 	// Try to parse as different types
 	if value == "" {
-		current[finalKey] = nil
+		return nil
 	} else if value == "true" {
-		current[finalKey] = true
+		return true
 	} else if value == "false" {
-		current[finalKey] = false
+		return false
 	} else if num, err := strconv.ParseFloat(value, 64); err == nil {
 		// Check if it's actually an integer
 		if num == float64(int64(num)) {
-			current[finalKey] = int64(num)
-		} else {
-			current[finalKey] = num
+			return int64(num)
 		}
+		return num
 	} else if m.looksLikeJSON(value) {
 		// Try to parse as JSON (for arrays and objects)
 		var jsonValue interface{}
 		if err := json.Unmarshal([]byte(value), &jsonValue); err == nil {
-			current[finalKey] = jsonValue
-			log.Debugf("Parsed JSON value for %s: %v", finalKey, jsonValue)
-		} else {
-			// If JSON parsing fails, treat as string
-			current[finalKey] = value
+			log.Debugf("Parsed JSON value: %v", jsonValue)
+			return jsonValue
 		}
-	} else {
-		// Default to string
-		current[finalKey] = value
 	}
-
-	return nil
+	// Default to string
+	return value
 }
 
 func (m *Model) looksLikeJSON(value string) bool {
@@ -1070,19 +1315,5 @@ func (m *Model) looksLikeJSON(value string) bool {
 }
 
 func formatFloatValue(value float64) string {
-	if math.IsNaN(value) || math.IsInf(value, 0) {
-		return fmt.Sprintf("%v", value)
-	}
-
-	if isWholeNumber(value) {
-		return strconv.FormatInt(int64(math.Round(value)), 10)
-	}
-
-	rounded := math.Round(value*1000) / 1000
-	return strconv.FormatFloat(rounded, 'f', 3, 64)
-}
-
-func isWholeNumber(value float64) bool {
-	const epsilon = 1e-9
-	return math.Abs(value-math.Round(value)) < epsilon
+	return query.FormatFloat(value)
 }
@@ -0,0 +1,223 @@
+package cutable
+
+import (
+	"cutl/internal/config"
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+)
+
+type highlightRule struct {
+	column    string
+	condition *query.Query
+	style     lipgloss.Style
+}
+
+var colorNames = map[string]string{
+	"black":   "0",
+	"red":     "1",
+	"green":   "2",
+	"yellow":  "3",
+	"blue":    "4",
+	"magenta": "5",
+	"cyan":    "6",
+	"white":   "7",
+	"gray":    "8",
+	"grey":    "8",
+	"dim":     "240",
+}
+
+// ParseHighlightRules reads rules written as `condition => style` separated by
+// semicolons, e.g. `.label == null => red; .score < 0.5 => yellow @.score`.
+// Style tokens are `fg:COLOR`, `bg:COLOR`, `bold`, `faint` or a bare color
+// for the foreground; `@QUERY` limits the rule to that column's cell.
+func ParseHighlightRules(text string) ([]config.HighlightRule, error) {
+	var rules []config.HighlightRule
+	for _, part := range SplitTopLevel(text, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		pieces := SplitTopLevel(part, "=>")
+		if len(pieces) != 2 {
+			return nil, fmt.Errorf("rule %q must look like 'condition => style'", part)
+		}
+
+		rule := config.HighlightRule{Condition: strings.TrimSpace(pieces[0])}
+		if rule.Condition == "" {
+			return nil, fmt.Errorf("rule %q has no condition", part)
+		}
+		if _, err := query.Compile(rule.Condition); err != nil {
+			return nil, fmt.Errorf("condition %q: %v", rule.Condition, err)
+		}
+
+		styleText := strings.TrimSpace(pieces[1])
+		if at := strings.Index(styleText, "@"); at >= 0 {
+			rule.Column = strings.TrimSpace(styleText[at+1:])
+			styleText = styleText[:at]
+		}
+
+		for _, token := range strings.Fields(styleText) {
+			switch {
+			case token == "bold":
+				rule.Bold = true
+			case token == "faint":
+				rule.Faint = true
+			case strings.HasPrefix(token, "fg:"):
+				rule.Foreground = strings.TrimPrefix(token, "fg:")
+			case strings.HasPrefix(token, "bg:"):
+				rule.Background = strings.TrimPrefix(token, "bg:")
+			default:
+				rule.Foreground = token
+			}
+		}
+
+		if rule.Foreground == "" && rule.Background == "" && !rule.Bold && !rule.Faint {
+			return nil, fmt.Errorf("rule %q has no style", part)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// FormatHighlightRules is the inverse of ParseHighlightRules and is used to
+// prefill the input with the active rules.
+func FormatHighlightRules(rules []config.HighlightRule) string {
+	parts := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		var tokens []string
+		if rule.Foreground != "" {
+			tokens = append(tokens, "fg:"+rule.Foreground)
+		}
+		if rule.Background != "" {
+			tokens = append(tokens, "bg:"+rule.Background)
+		}
+		if rule.Bold {
+			tokens = append(tokens, "bold")
+		}
+		if rule.Faint {
+			tokens = append(tokens, "faint")
+		}
+		if rule.Column != "" {
+			tokens = append(tokens, "@"+rule.Column)
+		}
+		parts = append(parts, fmt.Sprintf("%s => %s", rule.Condition, strings.Join(tokens, " ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// SplitTopLevel splits s on sep, ignoring separators inside string literals
+// and (), [] or {} groups so jq expressions survive intact.
+func SplitTopLevel(s, sep string) []string {
+	var (
+		parts    []string
+		depth    int
+		inString bool
+		escaped  bool
+		start    int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		default:
+			if depth == 0 && strings.HasPrefix(s[i:], sep) {
+				parts = append(parts, s[start:i])
+				i += len(sep) - 1
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func resolveColor(name string) lipgloss.Color {
+	if code, ok := colorNames[strings.ToLower(name)]; ok {
+		return lipgloss.Color(code)
+	}
+	return lipgloss.Color(name)
+}
+
+func (m *Model) SetHighlightRules(rules []config.HighlightRule) {
+	m.highlightRules = nil
+	for _, rule := range rules {
+		condition, err := query.Compile(rule.Condition)
+		if err != nil {
+			log.Warnf("Skipping highlight rule %q: %v", rule.Condition, err)
+			continue
+		}
+
+		style := lipgloss.NewStyle().Bold(rule.Bold).Faint(rule.Faint)
+		if rule.Foreground != "" {
+			style = style.Foreground(resolveColor(rule.Foreground))
+		}
+		if rule.Background != "" {
+			style = style.Background(resolveColor(rule.Background))
+		}
+
+		m.highlightRules = append(m.highlightRules, highlightRule{
+			column:    rule.Column,
+			condition: condition,
+			style:     style,
+		})
+	}
+	m.rules = append([]config.HighlightRule{}, rules...)
+	log.Debugf("Set %d highlight rules", len(m.highlightRules))
+}
+
+func (m *Model) HighlightRules() []config.HighlightRule {
+	return m.rules
+}
+
+// highlightsFor evaluates the rules against a single entry. The first
+// matching row rule styles the whole row, the first matching cell rule for a
+// column overrides it for that cell.
+func (m *Model) highlightsFor(entry editor.Entry) (*lipgloss.Style, map[string]lipgloss.Style) {
+	var (
+		rowStyle   *lipgloss.Style
+		cellStyles map[string]lipgloss.Style
+	)
+	for i := range m.highlightRules {
+		rule := &m.highlightRules[i]
+		if rule.column == "" && rowStyle != nil {
+			continue
+		}
+		if rule.column != "" {
+			if _, done := cellStyles[rule.column]; done {
+				continue
+			}
+		}
+		if !rule.condition.Truthy(entry.Data) {
+			continue
+		}
+		if rule.column == "" {
+			rowStyle = &rule.style
+			continue
+		}
+		if cellStyles == nil {
+			cellStyles = make(map[string]lipgloss.Style)
+		}
+		cellStyles[rule.column] = rule.style
+	}
+	return rowStyle, cellStyles
+}
@@ -0,0 +1,224 @@
+package cutable
+
+import (
+	"cutl/internal/audit"
+	"cutl/internal/dataset"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Operation is a change made to the rows while journaling is on, kept until
+// it is written to the audit log or a recorded script. Filtered is set when
+// the operation applied to exactly the rows shown by Filter, so it can be
+// replayed on another file; an empty Filter then means all rows. Expr is
+// the jq expression of a transform, Replace the settings of a find/replace.
+type Operation struct {
+	Kind     string
+	Time     time.Time
+	Paths    []string
+	Expr     string
+	Replace  *dataset.ReplaceOptions
+	Filter   string
+	Filtered bool
+	Rows     []RowChange
+}
+
+// RowChange is one affected row. Origin is its line in the file on disk, Old
+// and New are copies of its content before and after; New is nil for
+// deleted rows. Rows of operations that are not Filtered carry their
+// dataset.RowKeys key among the rows before the change, so a replay can
+// tell identical rows apart.
+type RowChange struct {
+	Origin int
+	Key    string
+	Old    any
+	New    any
+}
+
+// SetJournaling turns recording of row changes on or off.
+// Recorded operations are dropped either way.
+func (m *Model) SetJournaling(on bool) {
+	m.journaling = on
+	m.journal = nil
+}
+
+func (m *Model) Journaling() bool {
+	return m.journaling
+}
+
+// JournalLength is the number of operations not taken yet.
+func (m *Model) JournalLength() int {
+	return len(m.journal)
+}
+
+// TakeJournal returns the operations recorded since the last call and
+// forgets them.
+func (m *Model) TakeJournal() []Operation {
+	journal := m.journal
+	m.journal = nil
+	return journal
+}
+
+func (m *Model) journalDelete(lines map[int]struct{}) {
+	if !m.journaling {
+		return
+	}
+	origins := m.Origins()
+	op := m.newOperation(audit.OpDelete, lines)
+	keys := m.pickedKeys(op)
+	for _, entry := range m.rawEntries {
+		if _, ok := lines[entry.Line]; ok {
+			op.Rows = append(op.Rows, RowChange{Origin: origins[entry.Line], Key: keys[entry.Line], Old: cloneValue(entry.Data)})
+		}
+	}
+	if len(op.Rows) > 0 {
+		m.journal = append(m.journal, op)
+	}
+}
+
+// pendingChange is a change to rows in progress: the operation as it was
+// selected and copies of the targeted rows before the change.
+type pendingChange struct {
+	op       Operation
+	snapshot map[int]any
+	keys     map[int]string
+}
+
+// beginChange copies the targeted rows before they are changed, or returns
+// nil when journaling is off.
+func (m *Model) beginChange(kind string, lines []int) *pendingChange {
+	if !m.journaling {
+		return nil
+	}
+	targets := make(map[int]struct{}, len(lines))
+	for _, line := range lines {
+		targets[line] = struct{}{}
+	}
+	change := &pendingChange{op: m.newOperation(kind, targets), snapshot: make(map[int]any, len(lines))}
+	change.keys = m.pickedKeys(change.op)
+	for _, entry := range m.rawEntries {
+		if _, ok := targets[entry.Line]; ok {
+			change.snapshot[entry.Line] = cloneValue(entry.Data)
+		}
+	}
+	return change
+}
+
+// finishChange records the targeted rows whose content changed.
+func (m *Model) finishChange(change *pendingChange) {
+	if change == nil || !m.journaling {
+		return
+	}
+	origins := m.Origins()
+	op := change.op
+	for _, entry := range m.rawEntries {
+		old, ok := change.snapshot[entry.Line]
+		if !ok || reflect.DeepEqual(old, entry.Data) {
+			continue
+		}
+		op.Rows = append(op.Rows, RowChange{Origin: origins[entry.Line], Key: change.keys[entry.Line], Old: old, New: cloneValue(entry.Data)})
+	}
+	if len(op.Rows) == 0 {
+		return
+	}
+	// The edit view passes every column of a single row; keep the ones set.
+	var paths []string
+	for _, path := range op.Paths {
+		for _, row := range op.Rows {
+			if !reflect.DeepEqual(PathValue(row.Old, path), PathValue(row.New, path)) {
+				paths = append(paths, path)
+				break
+			}
+		}
+	}
+	op.Paths = paths
+	m.journal = append(m.journal, op)
+}
+
+// PathValue reads the value at a dotted path as set by the edit view, e.g.
+// .meta.source; it is nil when the path does not exist.
+func PathValue(data any, path string) any {
+	current := data
+	for _, part := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = object[part]
+	}
+	return current
+}
+
+// editPaths lists the paths an edit sets. Multi-row edits skip empty values,
+// so those paths are left out.
+func editPaths(values map[string]string, singleMode bool) []string {
+	var paths []string
+	for path, value := range values {
+		if singleMode || strings.TrimSpace(value) != "" {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// newOperation notes whether the targeted lines are exactly the rows shown
+// by a regular filter.
+func (m *Model) newOperation(kind string, targets map[int]struct{}) Operation {
+	op := Operation{Kind: kind, Time: time.Now()}
+	if m.isSpecialFilter(m.filterQuery) || len(targets) != len(m.filteredEntries) {
+		return op
+	}
+	for _, entry := range m.filteredEntries {
+		if _, ok := targets[entry.Line]; !ok {
+			return op
+		}
+	}
+	op.Filter = m.filterQuery
+	op.Filtered = true
+	return op
+}
+
+// pickedKeys keys the rows by line for operations on rows picked by hand;
+// filtered operations are replayed by their filter and need no keys.
+func (m *Model) pickedKeys(op Operation) map[int]string {
+	if op.Filtered {
+		return nil
+	}
+	return m.RowKeys()
+}
+
+// RowKeys keys every row by line with dataset.RowKeys, which tells rows
+// apart by content and, for identical rows, by their order.
+func (m *Model) RowKeys() map[int]string {
+	rows := make([]any, len(m.rawEntries))
+	for i, entry := range m.rawEntries {
+		rows[i] = entry.Data
+	}
+	keys := make(map[int]string, len(rows))
+	for i, key := range dataset.RowKeys(rows) {
+		keys[m.rawEntries[i].Line] = key
+	}
+	return keys
+}
+
+// cloneValue deep-copies decoded JSON, which is edited in place.
+func cloneValue(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		clone := make(map[string]any, len(typed))
+		for key, item := range typed {
+			clone[key] = cloneValue(item)
+		}
+		return clone
+	case []any:
+		clone := make([]any, len(typed))
+		for i, item := range typed {
+			clone[i] = cloneValue(item)
+		}
+		return clone
+	}
+	return value
+}
@@ -0,0 +1,44 @@
+package cutable
+
+// Origins maps every current line to its line in the file as last loaded or
+// written; lines are missing for rows that did not come from the file.
+func (m *Model) Origins() map[int]int {
+	origins := make(map[int]int, len(m.rawEntries))
+	for _, entry := range m.rawEntries {
+		if m.origins == nil {
+			origins[entry.Line] = entry.Line
+		} else if origin, ok := m.origins[entry.Line]; ok {
+			origins[entry.Line] = origin
+		}
+	}
+	return origins
+}
+
+// Origin returns the line of a row in the file as last loaded or written;
+// false for rows that did not come from the file.
+func (m *Model) Origin(line int) (int, bool) {
+	if m.origins == nil {
+		return line, true
+	}
+	origin, ok := m.origins[line]
+	return origin, ok
+}
+
+// ResetOrigins marks the current rows as the state of the file, after it
+// has been written.
+func (m *Model) ResetOrigins() {
+	m.origins = nil
+}
+
+// remapOrigins follows the line renumbering done after deletions.
+func (m *Model) remapOrigins(renumbered map[int]int) {
+	remapped := make(map[int]int, len(renumbered))
+	for line, newLine := range renumbered {
+		if m.origins == nil {
+			remapped[newLine] = line
+		} else if origin, ok := m.origins[line]; ok {
+			remapped[newLine] = origin
+		}
+	}
+	m.origins = remapped
+}
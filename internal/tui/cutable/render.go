@@ -0,0 +1,120 @@
+package cutable
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// renderTable draws the visible window of the table. It mirrors the layout of
+// the bubbles table but allows highlight rules to style individual rows and
+// cells, which the bubbles renderer has no hook for.
+func (m *Model) renderTable() string {
+	columns := m.table.Columns()
+	rows := m.table.Rows()
+
+	header := m.renderHeader(columns)
+	bodyHeight := m.tableHeight - lipgloss.Height(header)
+	if bodyHeight < 1 {
+		bodyHeight = 1
+	}
+
+	cursor := m.table.Cursor()
+	m.scrollToCursor(cursor, bodyHeight, len(rows))
+
+	lines := make([]string, 0, bodyHeight+1)
+	lines = append(lines, header)
+	end := m.viewStart + bodyHeight
+	if end > len(rows) {
+		end = len(rows)
+	}
+	for i := m.viewStart; i < end; i++ {
+		lines = append(lines, m.renderRow(i, columns, rows[i], i == cursor || m.inVisualRange(i)))
+	}
+	for len(lines) < bodyHeight+1 {
+		lines = append(lines, "")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m *Model) scrollToCursor(cursor, height, total int) {
+	if cursor < m.viewStart {
+		m.viewStart = cursor
+	}
+	if cursor >= m.viewStart+height {
+		m.viewStart = cursor - height + 1
+	}
+	if m.viewStart > total-height {
+		m.viewStart = total - height
+	}
+	if m.viewStart < 0 {
+		m.viewStart = 0
+	}
+}
+
+func (m *Model) renderHeader(columns []table.Column) string {
+	cells := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if col.Width <= 0 {
+			continue
+		}
+		style := lipgloss.NewStyle().Width(col.Width).MaxWidth(col.Width).Inline(true)
+		cells = append(cells, m.styles.Header.Render(style.Render(runewidth.Truncate(col.Title, col.Width, "…"))))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, cells...)
+}
+
+func (m *Model) renderRow(idx int, columns []table.Column, row table.Row, selected bool) string {
+	var (
+		rowStyle   *lipgloss.Style
+		cellStyles map[string]lipgloss.Style
+	)
+	// The selection style is applied to the whole line, so inner highlights
+	// would only punch holes into it.
+	if !selected && len(m.highlightRules) > 0 && idx < len(m.filteredEntries) {
+		rowStyle, cellStyles = m.highlightsFor(m.filteredEntries[idx])
+	}
+
+	queryOffset := 0
+	if len(columns) > 0 && columns[0].Title == "●" {
+		queryOffset = 1
+	}
+
+	cells := make([]string, 0, len(columns))
+	for i, value := range row {
+		if i >= len(columns) || columns[i].Width <= 0 {
+			continue
+		}
+		width := columns[i].Width
+		text := runewidth.Truncate(value, width, "…")
+		if i == 0 && queryOffset == 1 && !selected && text == value && idx < len(m.filteredEntries) {
+			// Tag squares carry their tag's color; the selection style
+			// would override it anyway.
+			line := m.filteredEntries[idx].Line
+			if tags := m.tagSymbols(line); tags != "" {
+				text = strings.TrimSuffix(text, tags) + m.styledTagSymbols(line)
+			}
+		}
+		content := lipgloss.NewStyle().Width(width).MaxWidth(width).Inline(true).Render(text)
+
+		cellStyle := m.styles.Cell
+		if rowStyle != nil {
+			cellStyle = m.styles.Cell.Inherit(*rowStyle)
+		}
+		if q := i - queryOffset; q >= 0 && q < len(m.columnQueries) {
+			if style, ok := cellStyles[m.columnQueries[q]]; ok {
+				cellStyle = m.styles.Cell.Inherit(style)
+			}
+		}
+		cells = append(cells, cellStyle.Render(content))
+	}
+
+	line := lipgloss.JoinHorizontal(lipgloss.Top, cells...)
+	if selected {
+		return m.styles.Selected.Render(line)
+	}
+	return line
+}
@@ -0,0 +1,83 @@
+package cutable
+
+import (
+	"cutl/internal/review"
+	"strings"
+)
+
+const reviewFilterPrefix = "__REVIEW_"
+
+var reviewSymbols = map[review.State]string{
+	review.Accepted: "✓",
+	review.Rejected: "⊘",
+	review.Flagged:  "⚑",
+}
+
+// SetReviews replaces the known review statuses, keyed by line.
+func (m *Model) SetReviews(reviews map[int]review.Status) {
+	m.reviews = reviews
+	m.rebuildTable()
+}
+
+// Review returns the status of a line; rows without one are unreviewed.
+func (m *Model) Review(line int) review.Status {
+	if status, ok := m.reviews[line]; ok {
+		return status
+	}
+	return review.Status{State: review.Unreviewed}
+}
+
+func (m *Model) SetReview(line int, status review.Status) {
+	if m.reviews == nil {
+		m.reviews = make(map[int]review.Status)
+	}
+	if status.State == review.Unreviewed {
+		delete(m.reviews, line)
+	} else {
+		m.reviews[line] = status
+	}
+	m.rebuildTable()
+}
+
+// ReviewCounts counts the filtered entries per review state.
+func (m *Model) ReviewCounts() map[review.State]int {
+	counts := make(map[review.State]int)
+	for _, entry := range m.filteredEntries {
+		counts[m.Review(entry.Line).State]++
+	}
+	return counts
+}
+
+func (m *Model) GenerateReviewFilter(state review.State) string {
+	if !m.isSpecialFilter(m.filterQuery) {
+		m.originalFilter = m.filterQuery
+	}
+	return reviewFilterPrefix + string(state) + "__"
+}
+
+func (m *Model) reviewFilterState(filter string) (review.State, bool) {
+	if !strings.HasPrefix(filter, reviewFilterPrefix) || !strings.HasSuffix(filter, "__") {
+		return "", false
+	}
+	state, err := review.ParseState(strings.TrimSuffix(strings.TrimPrefix(filter, reviewFilterPrefix), "__"))
+	return state, err == nil
+}
+
+func (m *Model) IsCurrentFilterReview(state review.State) bool {
+	current, ok := m.reviewFilterState(m.filterQuery)
+	return ok && current == state
+}
+
+// remapReviews follows the line renumbering done after deletions.
+func (m *Model) remapReviews(renumbered map[int]int) {
+	if len(m.reviews) == 0 {
+		return
+	}
+	remapped := make(map[int]review.Status, len(m.reviews))
+	for line, status := range m.reviews {
+		if newLine, ok := renumbered[line]; ok {
+			remapped[newLine] = status
+		}
+	}
+	m.reviews = remapped
+}
@@ -0,0 +1,105 @@
+package cutable
+
+import (
+	"cutl/internal/audit"
+	"cutl/internal/dataset"
+	"cutl/internal/query"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// TransformEntries replaces the given entries by the first output of the jq
+// expression. Nothing changes when the expression fails or yields nothing
+// for one of them. It returns the number of entries whose content changed.
+func (m *Model) TransformEntries(lines []int, expr string) (int, error) {
+	transform, err := query.Compile(expr)
+	if err != nil {
+		return 0, err
+	}
+	results, err := m.rewriteResults(lines, func(data any) (any, error) {
+		value, ok, err := transform.First(data)
+		if err == nil && !ok {
+			err = fmt.Errorf("%s yields no value", expr)
+		}
+		return value, err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	change := m.beginChange(audit.OpTransform, lines)
+	if change != nil {
+		change.op.Expr = expr
+	}
+	return m.rewriteEntries(results, change), nil
+}
+
+// ReplaceEntries runs a find/replace on the given entries. Nothing changes
+// when the scope cannot be evaluated for one of them. It returns the number
+// of entries changed and of matches replaced.
+func (m *Model) ReplaceEntries(lines []int, replacer *dataset.Replacer) (int, int, error) {
+	matches := 0
+	paths := make(map[string]struct{})
+	results, err := m.rewriteResults(lines, func(data any) (any, error) {
+		value, replacements, err := replacer.Replace(data)
+		for _, replacement := range replacements {
+			matches += replacement.Matches
+			paths[replacement.Path] = struct{}{}
+		}
+		return value, err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	change := m.beginChange(audit.OpReplace, lines)
+	if change != nil {
+		options := replacer.Options()
+		change.op.Replace = &options
+		for path := range paths {
+			change.op.Paths = append(change.op.Paths, path)
+		}
+		sort.Strings(change.op.Paths)
+	}
+	return m.rewriteEntries(results, change), matches, nil
+}
+
+// rewriteResults computes the new content of the given lines without
+// changing them yet.
+func (m *Model) rewriteResults(lines []int, rewrite func(any) (any, error)) (map[int]any, error) {
+	wanted := make(map[int]struct{}, len(lines))
+	for _, line := range lines {
+		wanted[line] = struct{}{}
+	}
+	results := make(map[int]any, len(lines))
+	for _, entry := range m.rawEntries {
+		if _, ok := wanted[entry.Line]; !ok {
+			continue
+		}
+		value, err := rewrite(entry.Data)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", entry.Line, err)
+		}
+		results[entry.Line] = value
+	}
+	return results, nil
+}
+
+// rewriteEntries stores the new content, journals the change and returns
+// the number of entries that changed.
+func (m *Model) rewriteEntries(results map[int]any, change *pendingChange) int {
+	changed := 0
+	for i := range m.rawEntries {
+		value, ok := results[m.rawEntries[i].Line]
+		if !ok || reflect.DeepEqual(value, m.rawEntries[i].Data) {
+			continue
+		}
+		m.rawEntries[i].Data = value
+		m.revalidate(&m.rawEntries[i])
+		changed++
+	}
+	m.finishChange(change)
+	m.rebuildTable()
+	return changed
+}
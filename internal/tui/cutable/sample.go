@@ -0,0 +1,38 @@
+package cutable
+
+const sampleFilter = "__SAMPLE__"
+
+// SetSample stores the sampled lines and returns the filter showing only
+// them. The current filter is kept to return to.
+func (m *Model) SetSample(lines []int) string {
+	m.sample = make(map[int]struct{}, len(lines))
+	for _, line := range lines {
+		m.sample[line] = struct{}{}
+	}
+	if !m.isSpecialFilter(m.filterQuery) {
+		m.originalFilter = m.filterQuery
+	}
+	return sampleFilter
+}
+
+func (m *Model) isSampleFilter(filter string) bool {
+	return filter == sampleFilter
+}
+
+func (m *Model) IsCurrentFilterSample() bool {
+	return m.isSampleFilter(m.filterQuery)
+}
+
+// remapSample follows the line renumbering done after deletions.
+func (m *Model) remapSample(renumbered map[int]int) {
+	if len(m.sample) == 0 {
+		return
+	}
+	remapped := make(map[int]struct{}, len(m.sample))
+	for line := range m.sample {
+		if newLine, ok := renumbered[line]; ok {
+			remapped[newLine] = struct{}{}
+		}
+	}
+	m.sample = remapped
+}
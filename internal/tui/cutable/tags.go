@@ -0,0 +1,181 @@
+package cutable
+
+import (
+	"cutl/internal/config"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	tagFilterPrefix = "__TAG_"
+	tagSymbol       = "■"
+)
+
+// SetTagDefinitions sets the known tags in hotkey order. Assignments of tags
+// that are no longer defined are dropped.
+func (m *Model) SetTagDefinitions(tags []config.Tag) {
+	m.tagDefs = append([]config.Tag{}, tags...)
+	defined := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		defined[tag.Name] = struct{}{}
+	}
+	for line, names := range m.tags {
+		for name := range names {
+			if _, ok := defined[name]; !ok {
+				delete(names, name)
+			}
+		}
+		if len(names) == 0 {
+			delete(m.tags, line)
+		}
+	}
+	m.rebuildTable()
+}
+
+func (m *Model) TagDefinitions() []config.Tag {
+	return m.tagDefs
+}
+
+// ToggleTag tags the marked entries, or the selected one without marks. If
+// all of them already carry the tag it is removed instead. It returns the
+// number of entries changed and whether the tag was added.
+func (m *Model) ToggleTag(name string) (int, bool) {
+	lines := m.MarkedLines()
+	if len(lines) == 0 {
+		if line := m.SelectedOriginalLine(); line > 0 {
+			lines = []int{line}
+		}
+	}
+	if len(lines) == 0 {
+		return 0, false
+	}
+
+	add := false
+	for _, line := range lines {
+		if _, ok := m.tags[line][name]; !ok {
+			add = true
+			break
+		}
+	}
+
+	if m.tags == nil {
+		m.tags = make(map[int]map[string]struct{})
+	}
+	for _, line := range lines {
+		if add {
+			if m.tags[line] == nil {
+				m.tags[line] = make(map[string]struct{})
+			}
+			m.tags[line][name] = struct{}{}
+		} else {
+			delete(m.tags[line], name)
+			if len(m.tags[line]) == 0 {
+				delete(m.tags, line)
+			}
+		}
+	}
+	m.rebuildTable()
+	return len(lines), add
+}
+
+// TaggedLines returns the lines carrying the tag, in file order.
+func (m *Model) TaggedLines(name string) []int {
+	var lines []int
+	for line, names := range m.tags {
+		if _, ok := names[name]; ok {
+			lines = append(lines, line)
+		}
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// SetTaggedLines replaces the assignments of one tag.
+func (m *Model) SetTaggedLines(name string, lines []int) {
+	if m.tags == nil {
+		m.tags = make(map[int]map[string]struct{})
+	}
+	for line, names := range m.tags {
+		delete(names, name)
+		if len(names) == 0 {
+			delete(m.tags, line)
+		}
+	}
+	for _, line := range lines {
+		if m.tags[line] == nil {
+			m.tags[line] = make(map[string]struct{})
+		}
+		m.tags[line][name] = struct{}{}
+	}
+	m.rebuildTable()
+}
+
+// TagsOf returns the tags of a line in definition order.
+func (m *Model) TagsOf(line int) []config.Tag {
+	var tags []config.Tag
+	for _, tag := range m.tagDefs {
+		if _, ok := m.tags[line][tag.Name]; ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// ReplaceMarks marks exactly the given lines, so the bulk actions working on
+// marks can be scoped to a tag.
+func (m *Model) ReplaceMarks(lines []int) {
+	m.marked = make(map[int]struct{}, len(lines))
+	for _, line := range lines {
+		m.marked[line] = struct{}{}
+	}
+	m.rebuildTable()
+}
+
+func (m *Model) GenerateTagFilter(name string) string {
+	if !m.isSpecialFilter(m.filterQuery) {
+		m.originalFilter = m.filterQuery
+	}
+	return tagFilterPrefix + name + "__"
+}
+
+func (m *Model) tagFilterName(filter string) (string, bool) {
+	if !strings.HasPrefix(filter, tagFilterPrefix) || !strings.HasSuffix(filter, "__") {
+		return "", false
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(filter, tagFilterPrefix), "__")
+	return name, name != ""
+}
+
+func (m *Model) IsCurrentFilterTag(name string) bool {
+	current, ok := m.tagFilterName(m.filterQuery)
+	return ok && current == name
+}
+
+func (m *Model) tagSymbols(line int) string {
+	return strings.Repeat(tagSymbol, len(m.TagsOf(line)))
+}
+
+// styledTagSymbols renders one square per tag in the tag's color.
+func (m *Model) styledTagSymbols(line int) string {
+	var b strings.Builder
+	for _, tag := range m.TagsOf(line) {
+		b.WriteString(lipgloss.NewStyle().Foreground(resolveColor(tag.Color)).Render(tagSymbol))
+	}
+	return b.String()
+}
+
+// remapTags follows the line renumbering done after deletions.
+func (m *Model) remapTags(renumbered map[int]int) {
+	if len(m.tags) == 0 {
+		return
+	}
+	remapped := make(map[int]map[string]struct{}, len(m.tags))
+	for line, names := range m.tags {
+		if newLine, ok := renumbered[line]; ok {
+			remapped[newLine] = names
+		}
+	}
+	m.tags = remapped
+}
@@ -0,0 +1,78 @@
+package cutable
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/schema"
+)
+
+// SetValidator attaches the schema used to revalidate entries after edits.
+func (m *Model) SetValidator(validator *schema.Validator) {
+	m.validator = validator
+}
+
+func (m *Model) Validator() *schema.Validator {
+	return m.validator
+}
+
+// SetViolations replaces the known violations, keyed by line.
+func (m *Model) SetViolations(violations map[int][]schema.Violation) {
+	m.violations = violations
+	m.rebuildTable()
+}
+
+func (m *Model) Violations(line int) []schema.Violation {
+	return m.violations[line]
+}
+
+func (m *Model) InvalidCount() int {
+	return len(m.violations)
+}
+
+func (m *Model) GenerateInvalidOnlyFilter() string {
+	if !m.isSpecialFilter(m.filterQuery) {
+		m.originalFilter = m.filterQuery
+	}
+	return "__INVALID_ONLY__"
+}
+
+func (m *Model) isInvalidOnlyFilter(filter string) bool {
+	return filter == "__INVALID_ONLY__"
+}
+
+func (m *Model) IsCurrentFilterInvalidOnly() bool {
+	return m.isInvalidOnlyFilter(m.filterQuery)
+}
+
+// IsCurrentFilterSpecial reports whether the active filter is one of the
+// internal state filters, which cannot be combined with jq expressions.
+func (m *Model) IsCurrentFilterSpecial() bool {
+	return m.isSpecialFilter(m.filterQuery)
+}
+
+func (m *Model) revalidate(entry *editor.Entry) {
+	if m.validator == nil {
+		return
+	}
+	if m.violations == nil {
+		m.violations = make(map[int][]schema.Violation)
+	}
+	if violations := m.validator.Validate(entry.Data); len(violations) > 0 {
+		m.violations[entry.Line] = violations
+	} else {
+		delete(m.violations, entry.Line)
+	}
+}
+
+// remapViolations follows the line renumbering done after deletions.
+func (m *Model) remapViolations(renumbered map[int]int) {
+	if len(m.violations) == 0 {
+		return
+	}
+	remapped := make(map[int][]schema.Violation, len(m.violations))
+	for line, violations := range m.violations {
+		if newLine, ok := renumbered[line]; ok {
+			remapped[newLine] = violations
+		}
+	}
+	m.violations = remapped
+}
@@ -0,0 +1,120 @@
+package cutable
+
+// StartVisual begins a range selection anchored at the selected entry. The
+// range follows the cursor until it is applied or stopped.
+func (m *Model) StartVisual() bool {
+	line := m.SelectedOriginalLine()
+	if line == 0 {
+		return false
+	}
+	m.visualAnchor = line
+	return true
+}
+
+func (m *Model) VisualActive() bool {
+	return m.visualAnchor > 0
+}
+
+func (m *Model) StopVisual() {
+	m.visualAnchor = 0
+}
+
+// visualRange returns the first and last index of the range in the filtered
+// entries. An anchor that left the filter collapses the range to the cursor.
+func (m *Model) visualRange() (int, int) {
+	cursor := m.table.Cursor()
+	anchor := cursor
+	for idx, entry := range m.filteredEntries {
+		if entry.Line == m.visualAnchor {
+			anchor = idx
+			break
+		}
+	}
+	if anchor > cursor {
+		return cursor, anchor
+	}
+	return anchor, cursor
+}
+
+func (m *Model) inVisualRange(idx int) bool {
+	if !m.VisualActive() {
+		return false
+	}
+	start, end := m.visualRange()
+	return idx >= start && idx <= end
+}
+
+// VisualCount returns the number of entries in the range.
+func (m *Model) VisualCount() int {
+	if !m.VisualActive() || len(m.filteredEntries) == 0 {
+		return 0
+	}
+	start, end := m.visualRange()
+	return end - start + 1
+}
+
+// ApplyVisual marks or unmarks every entry in the range and ends the range
+// selection. It returns the number of entries whose mark changed.
+func (m *Model) ApplyVisual(mark bool) int {
+	if !m.VisualActive() || len(m.filteredEntries) == 0 {
+		m.StopVisual()
+		return 0
+	}
+	start, end := m.visualRange()
+	m.StopVisual()
+	if end >= len(m.filteredEntries) {
+		end = len(m.filteredEntries) - 1
+	}
+
+	lines := make([]int, 0, end-start+1)
+	for _, entry := range m.filteredEntries[start : end+1] {
+		lines = append(lines, entry.Line)
+	}
+	if mark {
+		return m.MarkLines(lines)
+	}
+	return m.UnmarkLines(lines)
+}
+
+// UnmarkLines removes the marks of the given lines and returns how many of
+// them were marked before.
+func (m *Model) UnmarkLines(lines []int) int {
+	unmarked := 0
+	for _, line := range lines {
+		if _, ok := m.marked[line]; ok {
+			delete(m.marked, line)
+			unmarked++
+		}
+	}
+	m.rebuildTable()
+	return unmarked
+}
+
+// UnmarkVisible removes the marks of the filtered entries and keeps the
+// marks of hidden ones.
+func (m *Model) UnmarkVisible() int {
+	lines := make([]int, 0, len(m.filteredEntries))
+	for _, entry := range m.filteredEntries {
+		lines = append(lines, entry.Line)
+	}
+	return m.UnmarkLines(lines)
+}
+
+// InvertVisibleMarks flips the mark of every filtered entry and returns the
+// number of entries marked afterwards.
+func (m *Model) InvertVisibleMarks() int {
+	if m.marked == nil {
+		m.marked = make(map[int]struct{})
+	}
+	marked := 0
+	for _, entry := range m.filteredEntries {
+		if _, ok := m.marked[entry.Line]; ok {
+			delete(m.marked, entry.Line)
+		} else {
+			m.marked[entry.Line] = struct{}{}
+			marked++
+		}
+	}
+	m.rebuildTable()
+	return marked
+}
@@ -0,0 +1,324 @@
+package tui
+
+import (
+	"cutl/internal/dataset"
+	"cutl/internal/editor"
+	"cutl/internal/messages"
+	"cutl/internal/query"
+	"cutl/internal/tui/cutable"
+	"cutl/internal/tui/styles"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+const (
+	diffPending  = "pending"
+	diffAccepted = "accepted"
+	diffRejected = "rejected"
+)
+
+// diffHighlights color the change column unless the file has its own rules.
+const diffHighlights = `._diff.change == "added" => green @._diff.change; ` +
+	`._diff.change == "removed" => red @._diff.change; ` +
+	`._diff.change == "modified" => yellow @._diff.change; ` +
+	`._diff.status == "rejected" => faint`
+
+// SetDiffInputs shows the changes from oldPath to the model's file instead of
+// the file itself. Rows carry a virtual _diff column with the kind of change
+// and its review status; W writes oldPath with the accepted changes applied
+// to outputPath. It must be called before the program starts.
+func (m *Model) SetDiffInputs(oldPath, key, outputPath string) {
+	m.diffOldPath = oldPath
+	m.diffKey = key
+	m.diffOutput = outputPath
+}
+
+func (m *Model) isDiff() bool {
+	return m.diffOldPath != ""
+}
+
+func (m *Model) loadDiffCmd() tea.Msg {
+	oldEntries, _, err := editor.Load(m.diffOldPath, m.loadOptions)
+	if err != nil {
+		return messages.InputFileLoadError{Error: fmt.Errorf("%s: %w", m.diffOldPath, err)}
+	}
+	newEntries, header, err := editor.Load(m.jsonlPath, m.loadOptions)
+	if err != nil {
+		return messages.InputFileLoadError{Error: fmt.Errorf("%s: %w", m.jsonlPath, err)}
+	}
+	result, err := dataset.Diff(oldEntries, newEntries, m.diffKey)
+	if err != nil {
+		return messages.InputFileLoadError{Error: err}
+	}
+
+	rows := make([]editor.Entry, 0, len(result.Changes))
+	for _, change := range result.Changes {
+		source := change.New
+		if source == nil {
+			source = change.Old
+		}
+		// Rows that are not objects are shown wrapped in a value field.
+		object, ok := source.Data.(map[string]any)
+		if !ok {
+			object = map[string]any{"value": source.Data}
+		}
+		data := make(map[string]any, len(object)+1)
+		for key, value := range object {
+			data[key] = value
+		}
+		fields := make([]any, 0, len(change.Fields))
+		for _, field := range change.Fields {
+			fields = append(fields, field.Path)
+		}
+		info := map[string]any{
+			"change": string(change.Kind),
+			"status": diffPending,
+			"key":    change.Key,
+			"fields": fields,
+		}
+		if change.Old != nil {
+			info["old_line"] = float64(change.Old.Line)
+		}
+		if !ok {
+			info["wrapped"] = true
+		}
+		data[dataset.DiffField] = info
+		rows = append(rows, editor.Entry{Data: data, Line: len(rows) + 1})
+	}
+	return messages.DiffLoaded{Content: rows, Header: header, Old: oldEntries, Unchanged: result.Unchanged}
+}
+
+// handleDiffLoaded sets up the change columns and hands the rows to the
+// regular loading path.
+func (m *Model) handleDiffLoaded(msg messages.DiffLoaded) tea.Cmd {
+	m.diffOld = msg.Old
+	m.diffUnchanged = msg.Unchanged
+
+	columns := m.table.ColumnQueries()
+	if len(columns) == 0 && len(msg.Content) > 0 {
+		if first, ok := msg.Content[0].Data.(map[string]any); ok {
+			columns = cutable.DiscoverColumnQueries(first)
+		}
+	}
+	withDiff := []string{"._diff.change", "._diff.status"}
+	for _, column := range columns {
+		if !strings.Contains(column, dataset.DiffField) {
+			withDiff = append(withDiff, column)
+		}
+	}
+	m.table.SetColumnQueries(withDiff)
+	if len(m.table.HighlightRules()) == 0 {
+		if rules, err := cutable.ParseHighlightRules(diffHighlights); err == nil {
+			m.table.SetHighlightRules(rules)
+		}
+	}
+
+	return func() tea.Msg {
+		return messages.InputFileLoaded{Content: msg.Content, Header: msg.Header}
+	}
+}
+
+func (m *Model) showDiffSummary(rows []editor.Entry) {
+	counts := countDiff(rows)
+	m.setStatusMessage(fmt.Sprintf(
+		"%d added, %d removed, %d modified, %d unchanged since %s — + accept, - reject, W writes %s",
+		counts["added"], counts["removed"], counts["modified"], m.diffUnchanged,
+		filepath.Base(m.diffOldPath), filepath.Base(m.diffOutput),
+	), true)
+}
+
+// diffCounts counts the rows per change kind and per review status.
+func (m *Model) diffCounts() map[string]int {
+	return countDiff(m.table.Entries())
+}
+
+func countDiff(rows []editor.Entry) map[string]int {
+	counts := make(map[string]int)
+	for _, entry := range rows {
+		change, status := diffInfo(entry.Data)
+		counts[change]++
+		counts[status]++
+	}
+	return counts
+}
+
+func diffInfo(data any) (change, status string) {
+	object, _ := data.(map[string]any)
+	info, _ := object[dataset.DiffField].(map[string]any)
+	change, _ = info["change"].(string)
+	status, _ = info["status"].(string)
+	return change, status
+}
+
+// runDiffCommand handles `diff [accept|reject|reset] [--visible]`. Without
+// an action it reports the review progress.
+func (m *Model) runDiffCommand(args string, options map[string]string) (tea.Cmd, error) {
+	if !m.isDiff() {
+		return nil, fmt.Errorf("no diff open, start one with cutl diff OLD NEW --key EXPR -i")
+	}
+	_, visible := options["visible"]
+	switch args {
+	case "":
+		counts := m.diffCounts()
+		m.setStatusMessage(fmt.Sprintf("%d accepted, %d rejected, %d pending", counts[diffAccepted], counts[diffRejected], counts[diffPending]), true)
+		return nil, nil
+	case "accept":
+		return nil, m.decideChanges(diffAccepted, visible)
+	case "reject":
+		return nil, m.decideChanges(diffRejected, visible)
+	case "reset":
+		return nil, m.decideChanges(diffPending, visible)
+	}
+	return nil, fmt.Errorf("usage: diff [accept|reject|reset] [--visible]")
+}
+
+// decideChanges sets the review status of the marked or selected changes,
+// or of all filtered ones with visible.
+func (m *Model) decideChanges(status string, visible bool) error {
+	var lines []int
+	switch {
+	case visible:
+		lines = m.filteredLines()
+	case m.table.MarkedCount() > 0:
+		lines = m.table.MarkedLines()
+	default:
+		if entry := m.table.SelectedEntry(); entry != nil {
+			lines = []int{entry.Line}
+		}
+	}
+	if len(lines) == 0 {
+		return fmt.Errorf("no changes selected")
+	}
+	if err := m.table.UpdateEntries(lines, map[string]string{"._diff.status": status}, false); err != nil {
+		return err
+	}
+
+	counts := m.diffCounts()
+	m.setStatusMessage(fmt.Sprintf("%d changes %s — %d accepted, %d rejected, %d pending",
+		len(lines), status, counts[diffAccepted], counts[diffRejected], counts[diffPending]), true)
+	return nil
+}
+
+// decideSelectedChange accepts or rejects the marked or selected changes and
+// moves on when a single row was decided.
+func (m *Model) decideSelectedChange(status string) {
+	single := m.table.MarkedCount() == 0
+	if err := m.decideChanges(status, false); err != nil {
+		m.setStatusErrorMessage(err.Error(), true)
+		return
+	}
+	if single {
+		m.moveSelection(1)
+	}
+}
+
+// diffResultEntries applies the accepted rows to the old file. Rows are taken
+// from the table, so edits made while reviewing are part of the result.
+func (m *Model) diffResultEntries() ([]editor.Entry, int) {
+	oldByLine := make(map[int]*editor.Entry, len(m.diffOld))
+	for i := range m.diffOld {
+		oldByLine[m.diffOld[i].Line] = &m.diffOld[i]
+	}
+
+	var changes []dataset.Change
+	var accepted []bool
+	acceptedCount := 0
+	for _, entry := range m.table.Entries() {
+		object, _ := entry.Data.(map[string]any)
+		info, _ := object[dataset.DiffField].(map[string]any)
+		change, status := diffInfo(entry.Data)
+		row := dataset.WithoutFields([]editor.Entry{entry}, dataset.DiffField)[0]
+		if wrapped, _ := info["wrapped"].(bool); wrapped {
+			row.Data = object["value"]
+		}
+
+		item := dataset.Change{Kind: dataset.ChangeKind(change), New: &row}
+		if line, ok := info["old_line"].(float64); ok {
+			item.Old = oldByLine[int(line)]
+		}
+		changes = append(changes, item)
+		accepted = append(accepted, status == diffAccepted)
+		if status == diffAccepted {
+			acceptedCount++
+		}
+	}
+	return dataset.ApplyDiff(m.diffOld, changes, func(i int) bool { return accepted[i] }), acceptedCount
+}
+
+// requestDiffWriteConfirmation asks before writing the review result.
+func (m *Model) requestDiffWriteConfirmation() {
+	entries, accepted := m.diffResultEntries()
+	path := m.diffOutput
+	header := m.sourceHeader
+	m.pendingWriteCmd = func() tea.Msg {
+		if err := editor.Write(path, entries, header); err != nil {
+			return messages.InputFileWriteError{Error: err}
+		}
+		return messages.InputFileWritten{Path: path, Count: len(entries)}
+	}
+	m.confirmationActive = true
+	m.setStatusMessage(fmt.Sprintf("Write %s with %d accepted changes to %s? (y/N)",
+		filepath.Base(m.diffOldPath), accepted, filepath.Base(path)), false)
+}
+
+// renderDiffFields lists the field changes of a row against its old version.
+func (m *Model) renderDiffFields(entry *editor.Entry) string {
+	change, status := diffInfo(entry.Data)
+	if change == "" {
+		return ""
+	}
+	header := fmt.Sprintf("%s, %s", change, status)
+	switch change {
+	case string(dataset.ChangeAdded):
+		return styles.OkLabel.Render("+ " + header + ": new row")
+	case string(dataset.ChangeRemoved):
+		return styles.NoLabel.Render("- " + header + ": removed row")
+	}
+
+	object, _ := entry.Data.(map[string]any)
+	info, _ := object[dataset.DiffField].(map[string]any)
+	line, _ := info["old_line"].(float64)
+	var old *editor.Entry
+	for i := range m.diffOld {
+		if m.diffOld[i].Line == int(line) {
+			old = &m.diffOld[i]
+			break
+		}
+	}
+	if old == nil {
+		return styles.InfoLabel.Render("~ " + header)
+	}
+
+	current := dataset.WithoutFields([]editor.Entry{*entry}, dataset.DiffField)[0]
+	if wrapped, _ := info["wrapped"].(bool); wrapped {
+		current.Data = object["value"]
+	}
+	lines := []string{styles.Label.Render("~ " + header)}
+	for _, field := range dataset.DiffFields(old.Data, current.Data) {
+		lines = append(lines, styles.InfoLabel.Render(field.Path))
+		if !field.OldMissing {
+			lines = append(lines, styles.NoLabel.Render("  - "+diffValue(field.Old)))
+		}
+		if !field.NewMissing {
+			lines = append(lines, styles.OkLabel.Render("  + "+diffValue(field.New)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func diffValue(value any) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		log.Debugf("Cannot encode diff value: %v", err)
+		return query.Format(value)
+	}
+	return string(encoded)
+}
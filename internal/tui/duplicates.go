@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"cutl/internal/dataset"
+	"cutl/internal/messages"
+	"cutl/internal/tui/styles"
+	"fmt"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// runDedupeCommand handles `dedupe [EXPR] [--normalize] [--near 0.8]`.
+func (m *Model) runDedupeCommand(args string, options map[string]string) (tea.Cmd, error) {
+	opts := dataset.DuplicateOptions{KeyExpr: args}
+	if _, ok := options["normalize"]; ok {
+		opts.Normalize = true
+	}
+	if value, ok := options["near"]; ok {
+		similarity := 0.8
+		if value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || parsed <= 0 || parsed > 1 {
+				return nil, fmt.Errorf("--near expects a similarity between 0 and 1")
+			}
+			similarity = parsed
+		}
+		opts.Similarity = similarity
+		opts.Normalize = true
+	}
+
+	m.dupGeneration++
+	m.loading = true
+	m.loadingText = "Looking for duplicates..."
+
+	generation := m.dupGeneration
+	entries := m.table.FilteredEntries()
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		clusters, err := dataset.FindDuplicates(entries, opts)
+		return messages.DuplicatesFound{
+			Generation: generation,
+			Clusters:   clusters,
+			Error:      err,
+		}
+	}), nil
+}
+
+func (m *Model) handleDuplicatesFound(msg messages.DuplicatesFound) {
+	if msg.Generation != m.dupGeneration {
+		return
+	}
+	m.loading = false
+	if msg.Error != nil {
+		m.setStatusErrorMessage(fmt.Sprintf("Dedupe failed: %v", msg.Error), true)
+		return
+	}
+	if len(msg.Clusters) == 0 {
+		m.setStatusMessage("No duplicates found", true)
+		return
+	}
+
+	m.dupClusters = msg.Clusters
+	m.dupCursor = 0
+	m.dupOffset = 0
+	m.state = duplicatesView
+}
+
+func (m *Model) moveDupCursor(delta int) {
+	m.dupCursor += delta
+	if m.dupCursor >= len(m.dupClusters) {
+		m.dupCursor = len(m.dupClusters) - 1
+	}
+	if m.dupCursor < 0 {
+		m.dupCursor = 0
+	}
+}
+
+// markDuplicates marks all but the first entry of the given clusters.
+func (m *Model) markDuplicates(clusters []dataset.Cluster) {
+	marked := m.table.MarkLines(dataset.DuplicateLines(clusters))
+	m.setStatusMessage(fmt.Sprintf("Marked %d duplicate entries", marked), true)
+}
+
+func (m *Model) renderDuplicatesView(height int) string {
+	detailStyle := styles.DetailPanel
+	innerWidth := m.width - 8
+	if innerWidth > 0 {
+		detailStyle = detailStyle.Copy().Width(innerWidth)
+	} else {
+		detailStyle = detailStyle.Copy()
+	}
+
+	duplicates := len(dataset.DuplicateLines(m.dupClusters))
+	info := styles.InfoLabel.Render(fmt.Sprintf(
+		"%d clusters, %d removable duplicates — SPACE mark cluster, ENTER mark all, ESC return",
+		len(m.dupClusters), duplicates,
+	))
+
+	listHeight := height - 4
+	if listHeight < 1 {
+		listHeight = 1
+	}
+	if m.dupCursor < m.dupOffset {
+		m.dupOffset = m.dupCursor
+	}
+	if m.dupCursor >= m.dupOffset+listHeight {
+		m.dupOffset = m.dupCursor - listHeight + 1
+	}
+
+	labelWidth := innerWidth - 30
+	if labelWidth < 10 {
+		labelWidth = 10
+	}
+
+	lines := []string{info, ""}
+	end := m.dupOffset + listHeight
+	if end > len(m.dupClusters) {
+		end = len(m.dupClusters)
+	}
+	for i := m.dupOffset; i < end; i++ {
+		cluster := m.dupClusters[i]
+		line := fmt.Sprintf("%5d×  line %-7d %s", len(cluster.Lines), cluster.Lines[0], truncateValue(cluster.Label, labelWidth))
+		if i == m.dupCursor {
+			line = styles.ListSelected.Render(line)
+		} else {
+			line = styles.Text.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	return detailStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
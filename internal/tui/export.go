@@ -0,0 +1,241 @@
+package tui
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/export"
+	"cutl/internal/messages"
+	"cutl/internal/review"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+// runExportCommand writes a subset of the rows to another file, e.g.
+// `:export train.jsonl --rows marked --project {text, label} --move`. Rows
+// default to the marked entries, or the filtered ones without marks. With
+// --move the exported rows are removed from the table afterwards. CSV, TSV,
+// Markdown and JSON exports (chosen by --format or the file extension)
+// render the current columns instead of whole rows.
+func (m *Model) runExportCommand(args string, options map[string]string) (tea.Cmd, error) {
+	path := args
+	if path == "" {
+		return nil, fmt.Errorf("usage: export PATH [--rows filtered|marked|all] [--format csv|tsv|markdown|json] [--headers A,B] [--project EXPR] [--move]")
+	}
+	rows := ""
+	projection := ""
+	move := false
+	var headers []string
+	format, tabular := export.FormatForPath(path)
+	for name, value := range options {
+		switch name {
+		case "rows":
+			rows = value
+		case "project":
+			if value == "" {
+				return nil, fmt.Errorf("--project needs a jq expression")
+			}
+			projection = value
+		case "move":
+			move = true
+		case "format":
+			if value == "jsonl" {
+				tabular = false
+				break
+			}
+			parsed, err := export.ParseFormat(value)
+			if err != nil {
+				return nil, err
+			}
+			format, tabular = parsed, true
+		case "headers":
+			for _, header := range strings.Split(value, ",") {
+				headers = append(headers, strings.TrimSpace(header))
+			}
+		default:
+			return nil, fmt.Errorf("unknown option --%s", name)
+		}
+	}
+	if tabular && projection != "" {
+		return nil, fmt.Errorf("--project only applies to JSONL exports, %s exports use the current columns", format)
+	}
+	if !tabular && headers != nil {
+		return nil, fmt.Errorf("--headers only applies to csv, tsv, markdown and json exports")
+	}
+	if m.isInputPath(path) {
+		return nil, fmt.Errorf("%s is the open file, use W to write it", path)
+	}
+
+	entries, err := m.exportRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no entries to export")
+	}
+	var moved []string
+	if move {
+		keys := m.table.RowKeys()
+		for _, entry := range entries {
+			moved = append(moved, keys[entry.Line])
+		}
+	}
+	if projection != "" {
+		if entries, err = export.Project(entries, projection); err != nil {
+			return nil, err
+		}
+	} else if !tabular {
+		entries = m.withoutVirtualFields(entries)
+	}
+
+	if tabular {
+		table, err := export.Columns(entries, m.table.ColumnQueries(), headers)
+		if err != nil {
+			return nil, err
+		}
+		cmd := func() tea.Msg {
+			if err := table.WriteFile(path, format); err != nil {
+				return messages.InputFileWriteError{Error: err}
+			}
+			return messages.EntriesExported{Path: path, Count: len(table.Rows), Moved: moved}
+		}
+		return m.confirmOverwrite(cmd, path), nil
+	}
+
+	cmd := func() tea.Msg {
+		if err := editor.WriteJSONL(path, entries); err != nil {
+			return messages.InputFileWriteError{Error: err}
+		}
+		return messages.EntriesExported{Path: path, Count: len(entries), Moved: moved}
+	}
+	return m.confirmOverwrite(cmd, path), nil
+}
+
+// exportRows returns the entries selected by the --rows option.
+func (m *Model) exportRows(rows string) ([]editor.Entry, error) {
+	if rows == "" {
+		rows = "filtered"
+		if m.table.MarkedCount() > 0 {
+			rows = "marked"
+		}
+	}
+	switch rows {
+	case "all":
+		return m.table.Entries(), nil
+	case "filtered":
+		return m.table.FilteredEntries(), nil
+	case "marked":
+		marked := make(map[int]struct{})
+		for _, line := range m.table.MarkedLines() {
+			marked[line] = struct{}{}
+		}
+		var entries []editor.Entry
+		for _, entry := range m.table.Entries() {
+			if _, ok := marked[entry.Line]; ok {
+				entries = append(entries, entry)
+			}
+		}
+		return entries, nil
+	}
+	return nil, fmt.Errorf("unknown --rows %q (use filtered, marked or all)", rows)
+}
+
+// runSaveAsCommand writes all rows to a new path and continues editing
+// there. The format follows the extension, so `:saveas data.jsonl` converts
+// an imported CSV file. The file settings are carried over to the new path.
+func (m *Model) runSaveAsCommand(args string, options map[string]string) (tea.Cmd, error) {
+	path := args
+	if path == "" || len(options) > 0 {
+		return nil, fmt.Errorf("usage: saveas PATH")
+	}
+	if !m.isMerged() && m.isInputPath(path) {
+		return nil, fmt.Errorf("%s is the open file, use W to write it", path)
+	}
+
+	entries := m.withoutVirtualFields(m.table.Entries())
+	header := m.sourceHeader
+	cmd := func() tea.Msg {
+		if err := editor.Write(path, entries, header); err != nil {
+			return messages.InputFileWriteError{Error: err}
+		}
+		return messages.InputFileSavedAs{Path: path, Count: len(entries)}
+	}
+	return m.confirmOverwrite(cmd, path), nil
+}
+
+// confirmOverwrite asks before replacing existing files and runs cmd right
+// away otherwise.
+func (m *Model) confirmOverwrite(cmd tea.Cmd, paths ...string) tea.Cmd {
+	var existing []string
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			existing = append(existing, filepath.Base(path))
+		}
+	}
+	if len(existing) == 0 {
+		return cmd
+	}
+	m.pendingWriteCmd = cmd
+	m.confirmationActive = true
+	m.setStatusMessage(fmt.Sprintf("Overwrite %s? (y/N)", strings.Join(existing, ", ")), false)
+	return nil
+}
+
+func (m *Model) isInputPath(path string) bool {
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	input, err := filepath.Abs(m.jsonlPath)
+	return err == nil && target == input
+}
+
+func (m *Model) handleEntriesExported(msg messages.EntriesExported) {
+	name := filepath.Base(msg.Path)
+	if len(msg.Moved) == 0 {
+		m.setStatusMessage(fmt.Sprintf("Exported %d entries to %s", msg.Count, name), true)
+		return
+	}
+	// Rows are found by content, so deletes and edits made while the file
+	// was written don't remove the wrong rows; rows edited since stay.
+	moved := make(map[string]struct{}, len(msg.Moved))
+	for _, key := range msg.Moved {
+		moved[key] = struct{}{}
+	}
+	var lines []int
+	for line, key := range m.table.RowKeys() {
+		if _, ok := moved[key]; ok {
+			lines = append(lines, line)
+		}
+	}
+	removed := m.table.DeleteLines(lines)
+	if kept := len(msg.Moved) - removed; kept > 0 {
+		m.setStatusMessage(fmt.Sprintf("Moved %d entries to %s, %d changed or deleted meanwhile were left alone, press W to remove the moved ones from %s", removed, name, kept, filepath.Base(m.jsonlPath)), true)
+		return
+	}
+	m.setStatusMessage(fmt.Sprintf("Moved %d entries to %s, press W to remove them from %s", removed, name, filepath.Base(m.jsonlPath)), true)
+}
+
+func (m *Model) handleSavedAs(msg messages.InputFileSavedAs) {
+	fileConfig, _ := m.config.GetFileConfig(m.jsonlPath)
+	fileConfig.Session = nil
+	m.config.SetFileConfig(msg.Path, fileConfig)
+	if err := m.config.Save(); err != nil {
+		log.Warnf("Failed to copy settings to %s: %v", msg.Path, err)
+	}
+
+	m.flushAudit(msg.Path, m.jsonlPath)
+	m.jsonlPath = msg.Path
+	m.mergeInputs = nil
+	m.table.ResetOrigins()
+	m.indexDiskRows(m.table.Entries())
+	if m.reviewSidecar != nil && len(m.reviewSidecar.Rows) > 0 {
+		if err := m.saveReviewSidecar(review.SidecarPath(m.jsonlPath)); err != nil {
+			log.Warnf("Failed to copy reviews: %v", err)
+		}
+	}
+	m.setStatusMessage(fmt.Sprintf("Saved %d entries as %s", msg.Count, filepath.Base(msg.Path)), true)
+}
@@ -0,0 +1,220 @@
+package tui
+
+import (
+	"cutl/internal/messages"
+	"cutl/internal/stats"
+	"cutl/internal/tui/styles"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const facetBarWidth = 30
+
+// openFacets shows the value counts of the column the cursor is in.
+func (m *Model) openFacets() tea.Cmd {
+	if len(m.table.ColumnQueries()) == 0 {
+		return nil
+	}
+	m.state = facetView
+	return m.computeFacetsCmd(m.table.ColumnCursor())
+}
+
+func (m *Model) computeFacetsCmd(column int) tea.Cmd {
+	m.facetGeneration++
+	m.facetColumn = column
+	m.facetValues = nil
+	m.facetCursor = 0
+	m.facetOffset = 0
+	m.facetSelected = make(map[int]struct{})
+	m.loading = true
+	m.loadingText = "Counting values..."
+
+	generation := m.facetGeneration
+	expr := m.table.ColumnQueries()[column]
+	entries := m.table.FilteredEntries()
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		values, err := stats.Facet(entries, expr)
+		return messages.FacetComputed{
+			Generation: generation,
+			Column:     column,
+			Values:     values,
+			Error:      err,
+		}
+	})
+}
+
+func (m *Model) handleFacetComputed(msg messages.FacetComputed) {
+	if msg.Generation != m.facetGeneration {
+		return
+	}
+	m.loading = false
+	if msg.Error != nil {
+		m.state = tableView
+		m.setStatusErrorMessage(fmt.Sprintf("Facet failed: %v", msg.Error), true)
+		return
+	}
+	m.facetValues = msg.Values
+}
+
+func (m *Model) moveFacetCursor(delta int) {
+	m.facetCursor += delta
+	if m.facetCursor >= len(m.facetValues) {
+		m.facetCursor = len(m.facetValues) - 1
+	}
+	if m.facetCursor < 0 {
+		m.facetCursor = 0
+	}
+}
+
+func (m *Model) cycleFacetColumn(delta int) tea.Cmd {
+	columns := m.table.ColumnQueries()
+	if len(columns) < 2 {
+		return nil
+	}
+	next := (m.facetColumn + delta + len(columns)) % len(columns)
+	return m.computeFacetsCmd(next)
+}
+
+func (m *Model) toggleFacetSelection() {
+	if m.facetCursor >= len(m.facetValues) {
+		return
+	}
+	if _, ok := m.facetSelected[m.facetCursor]; ok {
+		delete(m.facetSelected, m.facetCursor)
+	} else {
+		m.facetSelected[m.facetCursor] = struct{}{}
+	}
+	m.moveFacetCursor(1)
+}
+
+// facetFilterCmd narrows the current filter down to the selected values, or
+// to the value under the cursor when nothing is selected.
+func (m *Model) facetFilterCmd() tea.Cmd {
+	if len(m.facetValues) == 0 {
+		return nil
+	}
+
+	indices := make([]int, 0, len(m.facetSelected))
+	for idx := range m.facetSelected {
+		indices = append(indices, idx)
+	}
+	if len(indices) == 0 {
+		indices = append(indices, m.facetCursor)
+	}
+	sort.Ints(indices)
+
+	literals := make([]string, len(indices))
+	for i, idx := range indices {
+		literals[i] = m.facetValues[idx].Literal
+	}
+
+	filter, ok := m.narrowFilter(stats.FacetFilter(m.table.ColumnQueries()[m.facetColumn], literals))
+	if !ok {
+		return nil
+	}
+
+	m.state = tableView
+	return func() tea.Msg {
+		return messages.FilterQueryChanged{Query: filter}
+	}
+}
+
+// narrowFilter combines a drill-down filter with the current one. The rows
+// of a special filter (marked, invalid, tagged, reviewed or sampled rows)
+// cannot be expressed in jq, so drilling down is refused there instead of
+// showing rows the counts did not cover.
+func (m *Model) narrowFilter(filter string) (string, bool) {
+	if m.table.IsCurrentFilterSpecial() {
+		m.setStatusErrorMessage("Counts cover the rows of a special filter, clear it with F to drill down", true)
+		return "", false
+	}
+	if current := m.table.FilterQuery(); current != "" {
+		filter = fmt.Sprintf("(%s) and (%s)", current, filter)
+	}
+	return filter, true
+}
+
+func (m *Model) renderFacetView(height int) string {
+	detailStyle := styles.DetailPanel
+	innerWidth := m.width - 8
+	if innerWidth > 0 {
+		detailStyle = detailStyle.Copy().Width(innerWidth)
+	} else {
+		detailStyle = detailStyle.Copy()
+	}
+
+	columns := m.table.ColumnQueries()
+	column := ""
+	if m.facetColumn < len(columns) {
+		column = columns[m.facetColumn]
+	}
+
+	total := 0
+	maxCount := 0
+	for _, v := range m.facetValues {
+		total += v.Count
+		if v.Count > maxCount {
+			maxCount = v.Count
+		}
+	}
+
+	info := styles.InfoLabel.Render(fmt.Sprintf(
+		"Values of %s (%d distinct) — ←/→ column, SPACE select, ENTER filter, ESC return",
+		column, len(m.facetValues),
+	))
+
+	listHeight := height - 4
+	if listHeight < 1 {
+		listHeight = 1
+	}
+	if m.facetCursor < m.facetOffset {
+		m.facetOffset = m.facetCursor
+	}
+	if m.facetCursor >= m.facetOffset+listHeight {
+		m.facetOffset = m.facetCursor - listHeight + 1
+	}
+
+	labelWidth := 0
+	for _, v := range m.facetValues {
+		if w := lipgloss.Width(truncateValue(v.Label, 40)); w > labelWidth {
+			labelWidth = w
+		}
+	}
+
+	lines := []string{info, ""}
+	end := m.facetOffset + listHeight
+	if end > len(m.facetValues) {
+		end = len(m.facetValues)
+	}
+	for i := m.facetOffset; i < end; i++ {
+		v := m.facetValues[i]
+		check := "[ ]"
+		if _, ok := m.facetSelected[i]; ok {
+			check = "[x]"
+		}
+		barLength := 0
+		if maxCount > 0 {
+			barLength = v.Count * facetBarWidth / maxCount
+		}
+		if barLength == 0 && v.Count > 0 {
+			barLength = 1
+		}
+		label := truncateValue(v.Label, 40)
+		line := fmt.Sprintf("%s %s%s  %7d  %-*s %5.1f%%",
+			check, label, strings.Repeat(" ", labelWidth-lipgloss.Width(label)),
+			v.Count, facetBarWidth, strings.Repeat("█", barLength),
+			float64(v.Count)*100/float64(total))
+		if i == m.facetCursor {
+			line = styles.ListSelected.Render(line)
+		} else {
+			line = styles.Text.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	return detailStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"cutl/internal/messages"
+	"cutl/internal/stats"
+	"cutl/internal/tui/cutable"
+	"cutl/internal/tui/styles"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// parseGroupBy reads `GROUP => agg, agg, ...`. Without aggregates only the
+// group sizes are shown.
+func parseGroupBy(text string) (string, []stats.Aggregate, error) {
+	parts := cutable.SplitTopLevel(text, "=>")
+	if len(parts) > 2 {
+		return "", nil, fmt.Errorf("expected 'group expression => aggregates'")
+	}
+
+	groupExpr := strings.TrimSpace(parts[0])
+	if groupExpr == "" {
+		return "", nil, fmt.Errorf("group expression is empty")
+	}
+
+	aggregates := []stats.Aggregate{}
+	if len(parts) == 2 {
+		for _, raw := range cutable.SplitTopLevel(parts[1], ",") {
+			if strings.TrimSpace(raw) == "" {
+				continue
+			}
+			agg, err := stats.ParseAggregate(raw)
+			if err != nil {
+				return "", nil, err
+			}
+			if agg.Kind == "count" {
+				// The group size is always shown.
+				continue
+			}
+			aggregates = append(aggregates, agg)
+		}
+	}
+	return groupExpr, aggregates, nil
+}
+
+func (m *Model) computeGroupByCmd(groupExpr string, aggregates []stats.Aggregate) tea.Cmd {
+	m.groupGeneration++
+	m.loading = true
+	m.loadingText = "Grouping..."
+
+	generation := m.groupGeneration
+	entries := m.table.FilteredEntries()
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		result, err := stats.GroupBy(entries, groupExpr, aggregates)
+		return messages.GroupByComputed{
+			Generation: generation,
+			Result:     result,
+			Error:      err,
+		}
+	})
+}
+
+func (m *Model) handleGroupByComputed(msg messages.GroupByComputed) {
+	if msg.Generation != m.groupGeneration {
+		return
+	}
+	m.loading = false
+	if msg.Error != nil {
+		m.setStatusErrorMessage(fmt.Sprintf("Group-by failed: %v", msg.Error), true)
+		return
+	}
+
+	m.groupResult = msg.Result
+	m.groupTable = newGroupTable(msg.Result, m.width-8)
+	m.state = groupView
+}
+
+func newGroupTable(result *stats.GroupResult, width int) table.Model {
+	titles := []string{result.Expr, "count"}
+	for _, agg := range result.Aggregates {
+		titles = append(titles, agg.Label)
+	}
+
+	rows := make([]table.Row, 0, len(result.Groups))
+	for _, group := range result.Groups {
+		row := table.Row{group.Label, fmt.Sprintf("%d", group.Count)}
+		row = append(row, group.Values...)
+		rows = append(rows, row)
+	}
+
+	columns := make([]table.Column, len(titles))
+	for i, title := range titles {
+		w := lipgloss.Width(title)
+		for _, row := range rows {
+			if cw := lipgloss.Width(row[i]); cw > w {
+				w = cw
+			}
+		}
+		if w > 40 {
+			w = 40
+		}
+		columns[i] = table.Column{Title: title, Width: w}
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithWidth(width),
+	)
+	t.SetStyles(cutable.DefaultStyles())
+	return t
+}
+
+// groupDrillDownCmd filters the main table down to the rows of the selected
+// group and leaves the group-by view.
+func (m *Model) groupDrillDownCmd() tea.Cmd {
+	if m.groupResult == nil {
+		return nil
+	}
+	cursor := m.groupTable.Cursor()
+	if cursor < 0 || cursor >= len(m.groupResult.Groups) {
+		return nil
+	}
+
+	filter, ok := m.narrowFilter(stats.GroupFilter(m.groupResult.Expr, m.groupResult.Groups[cursor].Literal))
+	if !ok {
+		return nil
+	}
+
+	m.state = tableView
+	return func() tea.Msg {
+		return messages.FilterQueryChanged{Query: filter}
+	}
+}
+
+func (m *Model) renderGroupView(height int) string {
+	groups := 0
+	if m.groupResult != nil {
+		groups = len(m.groupResult.Groups)
+	}
+	info := styles.InfoLabel.Render(fmt.Sprintf("%d groups — ENTER show rows, ESC return", groups))
+
+	m.groupTable.SetHeight(height - 2)
+	return lipgloss.JoinVertical(lipgloss.Left, info, "", m.groupTable.View())
+}
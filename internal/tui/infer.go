@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"cutl/internal/messages"
+	"cutl/internal/schema"
+	"cutl/internal/tui/styles"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// runInferCommand derives a schema from all loaded entries and shows it as a
+// tree. `--save PATH` changes where w/a write it to.
+func (m *Model) runInferCommand(args string, options map[string]string) (tea.Cmd, error) {
+	entries := m.table.Entries()
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no entries to infer a schema from")
+	}
+
+	m.inferSavePath = options["save"]
+	if m.inferSavePath == "" {
+		m.inferSavePath = defaultSchemaPath(m.jsonlPath)
+	}
+	m.loading = true
+	m.loadingText = "Inferring schema..."
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		return messages.SchemaInferred{Schema: schema.Infer(entries)}
+	}), nil
+}
+
+// defaultSchemaPath places the schema next to the input, data.jsonl becoming
+// data.schema.json.
+func defaultSchemaPath(jsonlPath string) string {
+	return strings.TrimSuffix(jsonlPath, filepath.Ext(jsonlPath)) + ".schema.json"
+}
+
+func (m *Model) handleSchemaInferred(msg messages.SchemaInferred) {
+	m.loading = false
+	m.inferredSchema = msg.Schema
+	m.state = inferView
+
+	lines := schema.Tree(msg.Schema)
+	for i, line := range lines {
+		name, description, _ := strings.Cut(line, ": ")
+		lines[i] = styles.Label.Render(name+":") + styles.Text.Render(" "+description)
+	}
+	m.inferViewport.SetContent(strings.Join(lines, "\n"))
+	m.inferViewport.GotoTop()
+}
+
+// saveInferredSchema writes the inferred schema to the save path, asking
+// first when the file exists. With attach it also becomes the schema entries
+// are validated against.
+func (m *Model) saveInferredSchema(attach bool) tea.Cmd {
+	data, err := json.MarshalIndent(m.inferredSchema, "", "  ")
+	if err != nil {
+		m.setStatusErrorMessage(fmt.Sprintf("Failed to encode schema: %v", err), true)
+		return nil
+	}
+	path := m.inferSavePath
+	cmd := func() tea.Msg {
+		err := os.WriteFile(path, append(data, '\n'), 0644)
+		return messages.InferredSchemaSaved{Path: path, Attach: attach, Error: err}
+	}
+	return m.confirmOverwrite(cmd, path)
+}
+
+func (m *Model) handleInferredSchemaSaved(msg messages.InferredSchemaSaved) tea.Cmd {
+	if msg.Error != nil {
+		m.setStatusErrorMessage(fmt.Sprintf("Failed to save schema: %v", msg.Error), true)
+		return nil
+	}
+	if !msg.Attach {
+		m.setStatusMessage(fmt.Sprintf("Schema saved to %s", msg.Path), true)
+		return nil
+	}
+
+	absPath, err := filepath.Abs(msg.Path)
+	if err != nil {
+		absPath = msg.Path
+	}
+	m.schemaPath = absPath
+	m.state = tableView
+	m.setStatusMessage(fmt.Sprintf("Schema saved to %s and attached", msg.Path), true)
+	return m.loadSchemaCmd()
+}
+
+func (m *Model) renderInferView() string {
+	detailStyle := styles.DetailPanel
+	innerWidth := m.width - 8
+	if innerWidth > 0 {
+		detailStyle = detailStyle.Copy().Width(innerWidth)
+	} else {
+		detailStyle = detailStyle.Copy()
+	}
+
+	info := styles.InfoLabel.Render(fmt.Sprintf(
+		"Inferred schema — W save to %s, A save and attach, ESC return", m.inferSavePath,
+	))
+
+	return detailStyle.Render(lipgloss.JoinVertical(lipgloss.Left, info, "", m.inferViewport.View()))
+}
@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/query"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// visualNavigationKeys move the cursor while a range is selected; every other
+// key except the ones acting on the range is ignored.
+var visualNavigationKeys = map[string]struct{}{
+	"up": {}, "down": {}, "k": {}, "j": {},
+	"pgup": {}, "pgdown": {}, "b": {}, "f": {},
+	"ctrl+u": {}, "ctrl+d": {}, "home": {}, "end": {}, "g": {}, "G": {},
+}
+
+func (m *Model) startVisual() {
+	if m.table.StartVisual() {
+		m.updateVisualProgress()
+	}
+}
+
+// handleVisualKey handles a key in the table while a range is selected and
+// reports whether the table should still see it.
+func (m *Model) handleVisualKey(key string) (bool, tea.Cmd) {
+	switch key {
+	case " ", "enter":
+		count := m.table.ApplyVisual(true)
+		m.commandPanel.SetProgress("")
+		m.setStatusMessage(fmt.Sprintf("Marked %d entries (%d marked)", count, m.table.MarkedCount()), true)
+	case "-":
+		count := m.table.ApplyVisual(false)
+		m.commandPanel.SetProgress("")
+		m.setStatusMessage(fmt.Sprintf("Unmarked %d entries (%d marked)", count, m.table.MarkedCount()), true)
+	case "esc", "ctrl+v":
+		m.table.StopVisual()
+		m.commandPanel.SetProgress("")
+	case "ctrl+c", "q":
+		return false, tea.Quit
+	default:
+		_, ok := visualNavigationKeys[key]
+		return ok, nil
+	}
+	return false, nil
+}
+
+func (m *Model) updateVisualProgress() {
+	m.commandPanel.SetProgress(fmt.Sprintf("-- RANGE -- %d rows · SPACE mark, - unmark, ESC cancel", m.table.VisualCount()))
+}
+
+// runMarkCommand adds the entries matching a jq expression to the marks, or
+// with --intersect keeps only the marked entries that match. The active
+// filter is left alone; --visible limits the match to the filtered entries.
+func (m *Model) runMarkCommand(args string, options map[string]string) (tea.Cmd, error) {
+	return m.markMatching(args, options, true)
+}
+
+// runUnmarkCommand removes the entries matching a jq expression from the
+// marks; without one it clears the marks of the visible entries.
+func (m *Model) runUnmarkCommand(args string, options map[string]string) (tea.Cmd, error) {
+	if args == "" {
+		for option := range options {
+			if option != "visible" {
+				return nil, fmt.Errorf("unknown option --%s for unmark", option)
+			}
+		}
+		unmarked := m.table.UnmarkVisible()
+		m.setStatusMessage(fmt.Sprintf("Unmarked %d visible entries (%d marked)", unmarked, m.table.MarkedCount()), true)
+		return nil, nil
+	}
+	return m.markMatching(args, options, false)
+}
+
+func (m *Model) markMatching(expr string, options map[string]string, mark bool) (tea.Cmd, error) {
+	name := "unmark"
+	if mark {
+		name = "mark"
+	}
+	if expr == "" {
+		return nil, fmt.Errorf("usage: mark EXPR [--intersect] [--visible]")
+	}
+	intersect := false
+	visible := false
+	for option := range options {
+		switch {
+		case option == "intersect" && mark:
+			intersect = true
+		case option == "visible":
+			visible = true
+		default:
+			return nil, fmt.Errorf("unknown option --%s for %s", option, name)
+		}
+	}
+
+	q, err := query.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	var entries []editor.Entry
+	if visible {
+		entries = m.table.FilteredEntries()
+	} else {
+		entries = m.table.Entries()
+	}
+	var matching []int
+	for _, entry := range entries {
+		if q.Truthy(entry.Data) {
+			matching = append(matching, entry.Line)
+		}
+	}
+
+	switch {
+	case intersect:
+		// Marks outside the considered entries are not touched.
+		keep := make(map[int]bool, len(entries))
+		for _, entry := range entries {
+			keep[entry.Line] = false
+		}
+		for _, line := range matching {
+			keep[line] = true
+		}
+		var remaining []int
+		dropped := 0
+		for _, line := range m.table.MarkedLines() {
+			if matched, considered := keep[line]; matched || !considered {
+				remaining = append(remaining, line)
+			} else {
+				dropped++
+			}
+		}
+		m.table.ReplaceMarks(remaining)
+		m.setStatusMessage(fmt.Sprintf("Unmarked %d entries not matching %s (%d marked)", dropped, expr, len(remaining)), true)
+	case mark:
+		count := m.table.MarkLines(matching)
+		m.setStatusMessage(fmt.Sprintf("Marked %d entries matching %s (%d marked)", count, expr, m.table.MarkedCount()), true)
+	default:
+		count := m.table.UnmarkLines(matching)
+		m.setStatusMessage(fmt.Sprintf("Unmarked %d entries matching %s (%d marked)", count, expr, m.table.MarkedCount()), true)
+	}
+	return nil, nil
+}
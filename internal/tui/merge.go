@@ -0,0 +1,299 @@
+package tui
+
+import (
+	"cutl/internal/dataset"
+	"cutl/internal/editor"
+	"cutl/internal/messages"
+	"cutl/internal/query"
+	"cutl/internal/tui/cutable"
+	"cutl/internal/tui/styles"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const sourceColumn = "._source.file"
+
+// SetMergeInputs opens several files as one table. The first path keys the
+// file settings; every row records its origin in the virtual _source column,
+// which is filterable but never written. It must be called before the
+// program starts.
+func (m *Model) SetMergeInputs(paths []string, options dataset.MergeOptions) {
+	m.mergeInputs = paths
+	m.mergeOptions = options
+	m.mergeOptions.Provenance = true
+	m.provenance = true
+}
+
+func (m *Model) isMerged() bool {
+	return len(m.mergeInputs) > 0
+}
+
+func (m *Model) loadMergedCmd() tea.Msg {
+	inputs, header, err := dataset.LoadMergeInputs(m.mergeInputs, m.loadOptions)
+	if err != nil {
+		return messages.InputFileLoadError{Error: err}
+	}
+	result, err := dataset.Merge(inputs, m.mergeOptions)
+	if err != nil {
+		return messages.InputFileLoadError{Error: err}
+	}
+	return messages.InputFilesMerged{
+		Content:   result.Entries,
+		Header:    header,
+		Conflicts: result.Conflicts,
+		Dropped:   result.Dropped,
+	}
+}
+
+// handleInputFilesMerged adds the source column and hands the rows to the
+// regular loading path.
+func (m *Model) handleInputFilesMerged(msg messages.InputFilesMerged) tea.Cmd {
+	m.conflicts = msg.Conflicts
+	m.conflictCursor = 0
+	m.mergeDropped = msg.Dropped
+
+	columns := m.table.ColumnQueries()
+	if len(columns) == 0 && len(msg.Content) > 0 {
+		if first, ok := msg.Content[0].Data.(map[string]any); ok {
+			columns = cutable.DiscoverColumnQueries(first)
+		}
+	}
+	hasSource := false
+	for _, column := range columns {
+		if strings.Contains(column, dataset.SourceField) {
+			hasSource = true
+		}
+	}
+	if !hasSource {
+		m.table.SetColumnQueries(append(append([]string{}, columns...), sourceColumn))
+	}
+
+	return func() tea.Msg {
+		return messages.InputFileLoaded{Content: msg.Content, Header: msg.Header}
+	}
+}
+
+// showMergeSummary reports the merge and opens pending conflicts.
+func (m *Model) showMergeSummary(rows int) {
+	summary := fmt.Sprintf("Merged %d files: %d rows", len(m.mergeInputs), rows)
+	if m.mergeDropped > 0 {
+		summary += fmt.Sprintf(", %d duplicates dropped", m.mergeDropped)
+	}
+	if len(m.conflicts) > 0 {
+		summary += fmt.Sprintf(", %d conflicts", len(m.conflicts))
+		m.state = conflictView
+	}
+	m.setStatusMessage(summary+", save with :saveas PATH", true)
+}
+
+// runConflictsCommand reopens the conflicts left after a merge.
+func (m *Model) runConflictsCommand(args string, options map[string]string) (tea.Cmd, error) {
+	if args != "" || len(options) > 0 {
+		return nil, fmt.Errorf("usage: conflicts")
+	}
+	if len(m.conflicts) == 0 {
+		return nil, fmt.Errorf("no unresolved merge conflicts")
+	}
+	if m.conflictCursor >= len(m.conflicts) {
+		m.conflictCursor = 0
+	}
+	m.state = conflictView
+	return nil, nil
+}
+
+// withoutVirtualFields drops the virtual _source and _diff columns before
+// rows are written, each only when this table added it.
+func (m *Model) withoutVirtualFields(entries []editor.Entry) []editor.Entry {
+	var fields []string
+	if m.provenance {
+		fields = append(fields, dataset.SourceField)
+	}
+	if m.isDiff() {
+		fields = append(fields, dataset.DiffField)
+	}
+	if len(fields) == 0 {
+		return entries
+	}
+	return dataset.WithoutFields(entries, fields...)
+}
+
+func (m *Model) handleConflictKey(key string) {
+	if len(m.conflicts) == 0 {
+		m.state = tableView
+		return
+	}
+	switch key {
+	case "esc":
+		m.state = tableView
+	case "left", "h", "k", "up":
+		if m.conflictCursor > 0 {
+			m.conflictCursor--
+		}
+	case "right", "l", "j", "down", "tab":
+		if m.conflictCursor < len(m.conflicts)-1 {
+			m.conflictCursor++
+		}
+	case "a", "A":
+		m.resolveConflicts(m.conflictCursor, m.conflictCursor+1, -1)
+	case "f", "F":
+		m.resolveConflicts(0, len(m.conflicts), 0)
+	case "L":
+		m.resolveConflicts(0, len(m.conflicts), len(m.conflicts[0].Versions))
+	default:
+		if len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
+			version := int(key[0] - '1')
+			if version < len(m.conflicts[m.conflictCursor].Versions) {
+				m.resolveConflicts(m.conflictCursor, m.conflictCursor+1, version)
+			}
+		}
+	}
+}
+
+// resolveConflicts keeps one version of the conflicts in [from, to) and
+// deletes the others. A negative version keeps all of them; a version past
+// the end keeps the last one of each conflict.
+func (m *Model) resolveConflicts(from, to, version int) {
+	current := make(map[string]int)
+	for _, entry := range m.table.Entries() {
+		if file, line, ok := dataset.SourceOf(entry.Data); ok {
+			current[fmt.Sprintf("%s:%d", file, line)] = entry.Line
+		}
+	}
+
+	var drop []int
+	for _, conflict := range m.conflicts[from:to] {
+		keep := version
+		if keep >= len(conflict.Versions) {
+			keep = len(conflict.Versions) - 1
+		}
+		for i, entry := range conflict.Versions {
+			if keep < 0 || i == keep {
+				continue
+			}
+			file, line, _ := dataset.SourceOf(entry.Data)
+			if current, ok := current[fmt.Sprintf("%s:%d", file, line)]; ok {
+				drop = append(drop, current)
+			}
+		}
+	}
+	removed := m.table.DeleteLines(drop)
+
+	m.conflicts = append(m.conflicts[:from], m.conflicts[to:]...)
+	if m.conflictCursor >= len(m.conflicts) {
+		m.conflictCursor = len(m.conflicts) - 1
+	}
+	if len(m.conflicts) == 0 {
+		m.conflictCursor = 0
+		m.state = tableView
+		m.setStatusMessage(fmt.Sprintf("All conflicts resolved, %d versions removed", removed), true)
+		return
+	}
+	m.setStatusMessage(fmt.Sprintf("%d versions removed, %d conflicts left", removed, len(m.conflicts)), true)
+}
+
+func (m *Model) renderConflictView(height int) string {
+	detailStyle := styles.DetailPanel
+	innerWidth := m.width - 8
+	if innerWidth > 0 {
+		detailStyle = detailStyle.Copy().Width(innerWidth)
+	} else {
+		detailStyle = detailStyle.Copy()
+	}
+	if len(m.conflicts) == 0 {
+		return detailStyle.Render(styles.Text.Render("No merge conflicts."))
+	}
+
+	conflict := m.conflicts[m.conflictCursor]
+	info := styles.InfoLabel.Render(fmt.Sprintf(
+		"Conflict %d of %d, key %s — 1-9 keep version, A keep all, F/SHIFT+L first/last wins for all, ←/→ browse, ESC return",
+		m.conflictCursor+1, len(m.conflicts), conflict.Key,
+	))
+
+	valueWidth := innerWidth - 24
+	if valueWidth < 10 {
+		valueWidth = 10
+	}
+	fields, equal := conflictFields(conflict.Versions)
+	lines := []string{info, ""}
+	for i, entry := range conflict.Versions {
+		source := fmt.Sprintf("line %d", entry.Line)
+		if file, line, ok := dataset.SourceOf(entry.Data); ok {
+			source = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+		}
+		lines = append(lines, lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			styles.CommandLabelTrigger.Render(fmt.Sprintf("%d ", i+1)),
+			styles.CommandLabel.Render(source),
+		))
+		object, isObject := entry.Data.(map[string]any)
+		if !isObject {
+			encoded, _ := json.Marshal(entry.Data)
+			lines = append(lines, styles.Text.Render("    "+truncateValue(string(encoded), valueWidth)))
+			continue
+		}
+		for _, field := range fields {
+			value := "—"
+			if v, ok := object[field]; ok {
+				value = query.Format(v)
+			}
+			lines = append(lines, fmt.Sprintf("    %s %s",
+				styles.InfoLabel.Render(truncateValue(field, 16)+":"),
+				styles.Text.Render(truncateValue(value, valueWidth))))
+		}
+		lines = append(lines, "")
+	}
+	if equal > 0 {
+		lines = append(lines, styles.InfoLabel.Render(fmt.Sprintf("%d equal fields hidden", equal)))
+	}
+	if maxLines := height - 2; maxLines > 0 && len(lines) > maxLines {
+		lines = append(lines[:maxLines-1], styles.InfoLabel.Render("…"))
+	}
+
+	return detailStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// conflictFields returns the sorted top-level fields whose values differ
+// between the versions and the number of fields they agree on.
+func conflictFields(versions []editor.Entry) ([]string, int) {
+	encoded := make(map[string]map[string]struct{})
+	for _, entry := range versions {
+		object, ok := entry.Data.(map[string]any)
+		if !ok {
+			continue
+		}
+		for field := range object {
+			if _, ok := encoded[field]; !ok {
+				encoded[field] = make(map[string]struct{})
+			}
+		}
+	}
+	delete(encoded, dataset.SourceField)
+
+	var fields []string
+	equal := 0
+	for field, values := range encoded {
+		for _, entry := range versions {
+			object, _ := entry.Data.(map[string]any)
+			value, ok := object[field]
+			if !ok {
+				values["\x00missing"] = struct{}{}
+				continue
+			}
+			data, _ := json.Marshal(value)
+			values[string(data)] = struct{}{}
+		}
+		if len(values) > 1 {
+			fields = append(fields, field)
+		} else {
+			equal++
+		}
+	}
+	sort.Strings(fields)
+	return fields, equal
+}
@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"cutl/internal/audit"
+	"cutl/internal/script"
+	"cutl/internal/tui/cutable"
+	"fmt"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runRecordCommand handles `record PATH` and `record stop`. Without arguments
+// it reports what is being recorded.
+func (m *Model) runRecordCommand(args string, options map[string]string) (tea.Cmd, error) {
+	if len(options) > 0 {
+		return nil, fmt.Errorf("usage: record [PATH|stop]")
+	}
+	switch args {
+	case "":
+		if m.recording == nil {
+			m.setStatusNeutralMessage("Not recording (start with :record script.yaml)", true)
+			return nil, nil
+		}
+		m.takeJournal()
+		m.setStatusMessage(fmt.Sprintf("Recording %d steps to %s, :record stop saves", len(m.recording.Steps), m.recordPath), true)
+		return nil, nil
+	case "stop":
+		if m.recording == nil {
+			return nil, fmt.Errorf("not recording")
+		}
+		m.takeJournal()
+		path, steps := m.recordPath, len(m.recording.Steps)
+		if err := m.FinishRecording(); err != nil {
+			return nil, fmt.Errorf("failed to save %s: %w", path, err)
+		}
+		m.setStatusMessage(fmt.Sprintf("Recorded %d steps to %s, replay with cutl apply %s FILE", steps, path, path), true)
+		return nil, nil
+	}
+
+	if m.recording != nil {
+		return nil, fmt.Errorf("already recording to %s, stop with :record stop", m.recordPath)
+	}
+	if m.isMerged() || m.isDiff() {
+		return nil, fmt.Errorf("recording needs a single file, save the table with :saveas first")
+	}
+	m.takeJournal()
+	m.recording = &script.Script{Source: filepath.Base(m.jsonlPath)}
+	m.recordPath = args
+	m.updateJournaling()
+	m.setStatusMessage(fmt.Sprintf("Recording deletes, edits, transforms and replaces to %s, :record stop saves", args), true)
+	return nil, nil
+}
+
+// FinishRecording writes the script being recorded, if any, and stops
+// recording.
+func (m *Model) FinishRecording() error {
+	if m.recording == nil {
+		return nil
+	}
+	m.takeJournal()
+	err := m.recording.Save(m.recordPath)
+	m.recording = nil
+	m.recordPath = ""
+	m.updateJournaling()
+	return err
+}
+
+// recordSteps turns operations into replayable steps. Operations on exactly
+// the filtered rows keep the filter; rows picked by hand are listed by their
+// row key, so only the picked copy of identical rows is replayed on.
+func (m *Model) recordSteps(operations []cutable.Operation) {
+	for _, operation := range operations {
+		step := script.Step{}
+		switch operation.Kind {
+		case audit.OpDelete:
+			step.Op = script.OpDelete
+		case audit.OpEdit:
+			if len(operation.Paths) == 0 {
+				continue
+			}
+			step.Op = script.OpEdit
+			step.Set = make(map[string]any, len(operation.Paths))
+			for _, path := range operation.Paths {
+				step.Set[path] = cutable.PathValue(operation.Rows[0].New, path)
+			}
+		case audit.OpTransform:
+			step.Op = script.OpTransform
+			step.Expr = operation.Expr
+		case audit.OpReplace:
+			step.Op = script.OpReplace
+			step.Replace = script.NewReplace(*operation.Replace)
+		default:
+			continue
+		}
+
+		if operation.Filtered {
+			step.Where = operation.Filter
+		} else {
+			for _, row := range operation.Rows {
+				step.Rows = append(step.Rows, row.Key)
+			}
+		}
+		m.recording.Steps = append(m.recording.Steps, step)
+	}
+}
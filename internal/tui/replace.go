@@ -0,0 +1,340 @@
+package tui
+
+import (
+	"cutl/internal/dataset"
+	"cutl/internal/messages"
+	"cutl/internal/tui/styles"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// replaceSamples caps the rows listed in the find/replace preview.
+const replaceSamples = 200
+
+const (
+	replaceFindInput = iota
+	replaceWithInput
+	replaceInInput
+)
+
+// runReplaceCommand handles `replace [FIND] [--in PATH] [--regex]
+// [--ignore-case] [--word]` by opening the find/replace dialog on the
+// marked rows, or on the filtered rows when none are marked.
+func (m *Model) runReplaceCommand(args string, options map[string]string) (tea.Cmd, error) {
+	var replaceOptions dataset.ReplaceOptions
+	for name, value := range options {
+		switch name {
+		case "in":
+			replaceOptions.In = value
+		case "regex":
+			replaceOptions.Regex = true
+		case "ignore-case":
+			replaceOptions.IgnoreCase = true
+		case "word":
+			replaceOptions.Word = true
+		default:
+			return nil, fmt.Errorf("unknown option --%s", name)
+		}
+	}
+	replaceOptions.Find = args
+
+	entries, err := m.exportRows("")
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no rows to search")
+	}
+	m.replaceRows = "filtered"
+	if m.table.MarkedCount() > 0 {
+		m.replaceRows = "marked"
+	}
+	m.replaceTargets = entries
+	m.openReplaceDialog(replaceOptions)
+	return m.replacePreviewCmd(), nil
+}
+
+func (m *Model) openReplaceDialog(options dataset.ReplaceOptions) {
+	placeholders := []string{"text or regular expression", "replacement, $1 or ${name} for captures", "column or jq path, empty for all strings"}
+	values := []string{options.Find, options.With, options.In}
+	m.replaceInputs = make([]textinput.Model, len(placeholders))
+	for i, placeholder := range placeholders {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.CharLimit = 500
+		input.Width = 50
+		input.SetValue(values[i])
+		m.replaceInputs[i] = input
+	}
+	m.replaceInputs[replaceFindInput].Focus()
+	m.replaceRegex = options.Regex
+	m.replaceIgnoreCase = options.IgnoreCase
+	m.replaceWord = options.Word
+	m.replacePreview = nil
+	m.replaceViewport.GotoTop()
+	m.state = replaceView
+}
+
+func (m *Model) replaceOptions() dataset.ReplaceOptions {
+	return dataset.ReplaceOptions{
+		Find:       m.replaceInputs[replaceFindInput].Value(),
+		With:       m.replaceInputs[replaceWithInput].Value(),
+		In:         strings.TrimSpace(m.replaceInputs[replaceInInput].Value()),
+		Regex:      m.replaceRegex,
+		IgnoreCase: m.replaceIgnoreCase,
+		Word:       m.replaceWord,
+	}
+}
+
+// handleReplaceKey handles a key in the find/replace dialog. Keys not used
+// by the dialog go to the focused input; every change refreshes the preview.
+func (m *Model) handleReplaceKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.closeReplaceDialog()
+		return nil
+	case "enter":
+		m.applyReplace()
+		return nil
+	case "tab":
+		m.focusReplaceInput(1)
+		return nil
+	case "shift+tab":
+		m.focusReplaceInput(-1)
+		return nil
+	case "pgdown":
+		m.replaceViewport.HalfPageDown()
+		return nil
+	case "pgup":
+		m.replaceViewport.HalfPageUp()
+		return nil
+	case "alt+r":
+		m.replaceRegex = !m.replaceRegex
+		return m.replacePreviewCmd()
+	case "alt+c":
+		m.replaceIgnoreCase = !m.replaceIgnoreCase
+		return m.replacePreviewCmd()
+	case "alt+w":
+		m.replaceWord = !m.replaceWord
+		return m.replacePreviewCmd()
+	case "up", "down":
+		if m.replaceInputs[replaceInInput].Focused() {
+			m.cycleReplaceScope(msg.String() == "down")
+			return m.replacePreviewCmd()
+		}
+		return nil
+	}
+
+	before := m.replaceOptions()
+	var cmds []tea.Cmd
+	for i := range m.replaceInputs {
+		var cmd tea.Cmd
+		m.replaceInputs[i], cmd = m.replaceInputs[i].Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.replaceOptions() != before {
+		cmds = append(cmds, m.replacePreviewCmd())
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *Model) focusReplaceInput(step int) {
+	for i := range m.replaceInputs {
+		if m.replaceInputs[i].Focused() {
+			m.replaceInputs[i].Blur()
+			next := (i + step + len(m.replaceInputs)) % len(m.replaceInputs)
+			m.replaceInputs[next].Focus()
+			return
+		}
+	}
+}
+
+// cycleReplaceScope steps the scope through all strings and the table's
+// columns.
+func (m *Model) cycleReplaceScope(forward bool) {
+	scopes := append([]string{""}, m.table.ColumnQueries()...)
+	current := strings.TrimSpace(m.replaceInputs[replaceInInput].Value())
+	index := -1
+	for i, scope := range scopes {
+		if scope == current {
+			index = i
+			break
+		}
+	}
+	switch {
+	case index < 0:
+		index = 0
+	case forward:
+		index = (index + 1) % len(scopes)
+	default:
+		index = (index - 1 + len(scopes)) % len(scopes)
+	}
+	m.replaceInputs[replaceInInput].SetValue(scopes[index])
+	m.replaceInputs[replaceInInput].CursorEnd()
+}
+
+// replacePreviewCmd runs the find/replace on copies of the target rows in
+// the background. Results of older runs are dropped by comparing the
+// generation.
+func (m *Model) replacePreviewCmd() tea.Cmd {
+	m.replaceGeneration++
+	generation := m.replaceGeneration
+	options := m.replaceOptions()
+	entries := m.replaceTargets
+	if options.Find == "" {
+		m.replacePreview = &messages.ReplacePreviewReady{Generation: generation, Targets: len(entries)}
+		return nil
+	}
+	return func() tea.Msg {
+		preview := messages.ReplacePreviewReady{Generation: generation, Targets: len(entries)}
+		replacer, err := dataset.NewReplacer(options)
+		if err != nil {
+			preview.Error = err
+			return preview
+		}
+		for _, entry := range entries {
+			_, replacements, err := replacer.Replace(entry.Data)
+			if err != nil {
+				preview.Error = fmt.Errorf("line %d: %w", entry.Line, err)
+				return preview
+			}
+			if len(replacements) == 0 {
+				continue
+			}
+			preview.Rows++
+			for _, replacement := range replacements {
+				preview.Matches += replacement.Matches
+			}
+			if len(preview.Samples) < replaceSamples {
+				preview.Samples = append(preview.Samples, messages.ReplaceSample{Line: entry.Line, Replacements: replacements})
+			}
+		}
+		return preview
+	}
+}
+
+func (m *Model) handleReplacePreviewReady(msg messages.ReplacePreviewReady) {
+	if msg.Generation != m.replaceGeneration {
+		return
+	}
+	m.replacePreview = &msg
+	m.replaceViewport.GotoTop()
+}
+
+// applyReplace runs the find/replace on the target rows and returns to the
+// table; errors keep the dialog open.
+func (m *Model) applyReplace() {
+	replacer, err := dataset.NewReplacer(m.replaceOptions())
+	if err != nil {
+		m.setStatusErrorMessage(err.Error(), true)
+		return
+	}
+	lines := make([]int, len(m.replaceTargets))
+	for i, entry := range m.replaceTargets {
+		lines[i] = entry.Line
+	}
+	rows, matches, err := m.table.ReplaceEntries(lines, replacer)
+	if err != nil {
+		m.setStatusErrorMessage(fmt.Sprintf("replace: %v", err), true)
+		return
+	}
+	m.closeReplaceDialog()
+	if matches == 0 {
+		m.setStatusNeutralMessage("No matches, nothing replaced", true)
+		return
+	}
+	m.setStatusMessage(fmt.Sprintf("Replaced %d matches in %d rows, press W to write", matches, rows), true)
+}
+
+func (m *Model) closeReplaceDialog() {
+	m.state = tableView
+	m.replaceInputs = nil
+	m.replaceTargets = nil
+	m.replacePreview = nil
+	m.replaceGeneration++
+}
+
+// renderReplaceLines lists every affected row with each changed value
+// before and after.
+func (m *Model) renderReplaceLines() string {
+	width := m.replaceViewport.Width
+	if width < 10 {
+		width = 10
+	}
+	preview := m.replacePreview
+	switch {
+	case preview == nil:
+		return styles.InfoLabel.Render("Searching…")
+	case preview.Error != nil:
+		return styles.NoLabel.Render(truncateValue(preview.Error.Error(), width))
+	case len(preview.Samples) == 0:
+		return styles.Text.Render("No matches.")
+	}
+
+	var lines []string
+	for _, sample := range preview.Samples {
+		for _, replacement := range sample.Replacements {
+			lines = append(lines,
+				styles.Label.Render(fmt.Sprintf("line %d %s", sample.Line, replacement.Path)),
+				styles.NoLabel.Render(truncateValue("  - "+diffValue(replacement.Old), width)),
+				styles.OkLabel.Render(truncateValue("  + "+diffValue(replacement.New), width)),
+			)
+		}
+	}
+	if preview.Rows > len(preview.Samples) {
+		lines = append(lines, styles.InfoLabel.Render(fmt.Sprintf("… %d more rows", preview.Rows-len(preview.Samples))))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *Model) renderReplaceView() string {
+	detailStyle := styles.DetailPanel
+	innerWidth := m.width - 8
+	if innerWidth > 0 {
+		detailStyle = detailStyle.Copy().Width(innerWidth)
+	} else {
+		detailStyle = detailStyle.Copy()
+	}
+
+	labels := []string{"Find", "Replace with", "In"}
+	sections := []string{
+		styles.InfoLabel.Render(fmt.Sprintf("Find and replace in %d %s rows", len(m.replaceTargets), m.replaceRows)),
+		"",
+	}
+	for i, input := range m.replaceInputs {
+		sections = append(sections, labels[i]+":", input.View())
+	}
+	sections = append(sections, "", strings.Join([]string{
+		replaceFlag("regex", "alt+r", m.replaceRegex),
+		replaceFlag("ignore case", "alt+c", m.replaceIgnoreCase),
+		replaceFlag("whole word", "alt+w", m.replaceWord),
+	}, "  "))
+
+	summary := ""
+	if preview := m.replacePreview; preview != nil && preview.Error == nil && m.replaceOptions().Find != "" {
+		summary = fmt.Sprintf("%d matches in %d of %d rows", preview.Matches, preview.Rows, preview.Targets)
+	}
+	sections = append(sections, styles.Label.Render(summary), "")
+
+	// The preview gets what is left below the inputs; lines are cut to the
+	// viewport, which is only sized while rendering.
+	m.replaceViewport.Height -= len(sections) + 1
+	if m.replaceViewport.Height < 1 {
+		m.replaceViewport.Height = 1
+	}
+	m.replaceViewport.SetContent(m.renderReplaceLines())
+	sections = append(sections, m.replaceViewport.View(), styles.InfoLabel.Render("Enter replace, ESC cancel, Tab next field, ↑/↓ pick a column for In, PgUp/PgDn scroll"))
+
+	return detailStyle.Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+func replaceFlag(name, key string, on bool) string {
+	if on {
+		return styles.OkLabel.Render(fmt.Sprintf("[x] %s (%s)", name, key))
+	}
+	return styles.Text.Render(fmt.Sprintf("[ ] %s (%s)", name, key))
+}
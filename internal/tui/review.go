@@ -0,0 +1,308 @@
+package tui
+
+import (
+	"cutl/internal/config"
+	"cutl/internal/editor"
+	"cutl/internal/messages"
+	"cutl/internal/query"
+	"cutl/internal/review"
+	"cutl/internal/tui/styles"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// reviewField returns the path review statuses are stored at inside rows,
+// or "" when they live in the sidecar file.
+func (m *Model) reviewField() string {
+	fileConfig, _ := m.config.GetFileConfig(m.jsonlPath)
+	if fileConfig.Review == nil {
+		return ""
+	}
+	return fileConfig.Review.Field
+}
+
+// loadReviewsCmd reads the review statuses of all entries from the review
+// field or the sidecar, in the background. Sidecar statuses are looked up by
+// the keys the rows have in the file, so unsaved edits don't hide them.
+func (m *Model) loadReviewsCmd(entries []editor.Entry) tea.Cmd {
+	field := m.reviewField()
+	sidecarPath := review.SidecarPath(m.jsonlPath)
+	keys := make(map[int]string, len(entries))
+	for _, entry := range entries {
+		if key, ok := m.diskKey(entry.Line); ok {
+			keys[entry.Line] = key
+		}
+	}
+	return func() tea.Msg {
+		reviews := make(map[int]review.Status)
+		if field != "" {
+			q, err := query.Compile(field)
+			if err != nil {
+				return messages.ReviewsLoaded{Error: err}
+			}
+			for _, entry := range entries {
+				value, _, err := q.First(entry.Data)
+				if err != nil {
+					continue
+				}
+				if status, ok := review.FromField(value); ok {
+					reviews[entry.Line] = status
+				}
+			}
+			return messages.ReviewsLoaded{Reviews: reviews}
+		}
+
+		sidecar, err := review.LoadSidecar(sidecarPath)
+		if err != nil {
+			return messages.ReviewsLoaded{Error: err}
+		}
+		if len(sidecar.Rows) > 0 {
+			for _, entry := range entries {
+				key, ok := keys[entry.Line]
+				if !ok {
+					continue
+				}
+				if status, ok := sidecar.Get(key); ok {
+					reviews[entry.Line] = status
+				}
+			}
+		}
+		return messages.ReviewsLoaded{Reviews: reviews, Sidecar: sidecar}
+	}
+}
+
+func (m *Model) handleReviewsLoaded(msg messages.ReviewsLoaded) {
+	if msg.Error != nil {
+		m.setStatusErrorMessage(fmt.Sprintf("Failed to load reviews: %v", msg.Error), true)
+		return
+	}
+	m.reviewSidecar = msg.Sidecar
+	m.table.SetReviews(msg.Reviews)
+}
+
+// runReviewCommand enters the review mode. With a state argument it toggles
+// the filter for that state instead; `--field PATH` and `--sidecar` choose
+// where statuses are stored.
+func (m *Model) runReviewCommand(args string, options map[string]string) (tea.Cmd, error) {
+	if len(options) > 0 {
+		return m.switchReviewStorage(options)
+	}
+
+	switch args {
+	case "":
+		if m.table.FilteredRows() == 0 {
+			return nil, fmt.Errorf("no entries to review")
+		}
+		m.state = reviewView
+		m.detailViewport.GotoTop()
+		m.updateDetailContent(m.table.SelectedEntry(), true)
+		m.updateReviewProgress()
+		return nil, nil
+	case "next":
+		m.nextUnreviewed()
+		return nil, nil
+	}
+
+	state, err := review.ParseState(args)
+	if err != nil {
+		return nil, err
+	}
+	var filter string
+	if m.table.IsCurrentFilterReview(state) {
+		filter = m.table.GetOriginalFilter()
+	} else {
+		filter = m.table.GenerateReviewFilter(state)
+	}
+	return func() tea.Msg {
+		return messages.FilterQueryChanged{Query: filter}
+	}, nil
+}
+
+func (m *Model) switchReviewStorage(options map[string]string) (tea.Cmd, error) {
+	var reviewConfig *config.ReviewConfig
+	for name, value := range options {
+		switch name {
+		case "field":
+			if !strings.HasPrefix(value, ".") {
+				return nil, fmt.Errorf("--field must be a path like .review")
+			}
+			reviewConfig = &config.ReviewConfig{Field: value}
+		case "sidecar":
+			reviewConfig = nil
+		default:
+			return nil, fmt.Errorf("unknown option --%s", name)
+		}
+	}
+	if err := m.config.UpdateReview(m.jsonlPath, reviewConfig); err != nil {
+		return nil, fmt.Errorf("failed to save review settings: %w", err)
+	}
+
+	if reviewConfig != nil {
+		m.setStatusMessage(fmt.Sprintf("Review statuses are stored in %s", reviewConfig.Field), true)
+	} else {
+		m.setStatusMessage(fmt.Sprintf("Review statuses are stored in %s", review.SidecarPath(m.jsonlPath)), true)
+	}
+	return m.loadReviewsCmd(m.table.Entries()), nil
+}
+
+// setReview records the status of the selected entry and moves on to the
+// next unreviewed one.
+func (m *Model) setReview(status review.Status) error {
+	entry := m.table.SelectedEntry()
+	if entry == nil {
+		return fmt.Errorf("no entry selected")
+	}
+	line := entry.Line
+	lines := m.filteredLines()
+
+	if field := m.reviewField(); field != "" {
+		value := ""
+		if status.State != review.Unreviewed {
+			encoded, err := json.Marshal(status)
+			if err != nil {
+				return err
+			}
+			value = string(encoded)
+		}
+		if err := m.table.UpdateEntries([]int{line}, map[string]string{field: value}, true); err != nil {
+			return err
+		}
+	} else {
+		if m.reviewSidecar == nil {
+			m.reviewSidecar = &review.Sidecar{Rows: make(map[string]review.Status)}
+		}
+		// The sidecar describes the file on disk; rows not written yet get
+		// their status saved with the next write.
+		if key, ok := m.diskKey(line); ok {
+			m.reviewSidecar.Set(key, status)
+			if err := m.reviewSidecar.Save(review.SidecarPath(m.jsonlPath)); err != nil {
+				return fmt.Errorf("failed to save reviews: %w", err)
+			}
+		}
+	}
+
+	m.table.SetReview(line, status)
+	if status.State == review.Unreviewed {
+		m.table.SelectLine(line)
+	} else {
+		m.advanceToUnreviewed(lines, line)
+	}
+	m.updateReviewProgress()
+	m.updateDetailContent(m.table.SelectedEntry(), true)
+	return nil
+}
+
+// saveReviewSidecar rebuilds the sidecar from the rows as they were just
+// written and saves it to path. Statuses follow their rows by line, but
+// edits, transforms and replaces change the row keys the sidecar is keyed
+// by, so without rebuilding those rows would come back unreviewed.
+func (m *Model) saveReviewSidecar(path string) error {
+	if m.reviewSidecar == nil || m.reviewField() != "" {
+		return nil
+	}
+	sidecar := &review.Sidecar{Rows: make(map[string]review.Status)}
+	for _, entry := range m.table.Entries() {
+		if key, ok := m.diskKey(entry.Line); ok {
+			sidecar.Set(key, m.table.Review(entry.Line))
+		}
+	}
+	if len(sidecar.Rows) == 0 && len(m.reviewSidecar.Rows) == 0 {
+		return nil
+	}
+	m.reviewSidecar = sidecar
+	return sidecar.Save(path)
+}
+
+func (m *Model) nextUnreviewed() {
+	entry := m.table.SelectedEntry()
+	if entry == nil {
+		return
+	}
+	m.advanceToUnreviewed(m.filteredLines(), entry.Line)
+	if m.state == reviewView {
+		m.updateDetailContent(m.table.SelectedEntry(), true)
+	}
+}
+
+// advanceToUnreviewed selects the first unreviewed entry after line in the
+// given order, wrapping around.
+func (m *Model) advanceToUnreviewed(lines []int, line int) {
+	start := 0
+	for i, l := range lines {
+		if l == line {
+			start = i + 1
+			break
+		}
+	}
+	for i := 0; i < len(lines); i++ {
+		candidate := lines[(start+i)%len(lines)]
+		if m.table.Review(candidate).State == review.Unreviewed && m.table.SelectLine(candidate) {
+			return
+		}
+	}
+	if !m.table.SelectLine(line) && len(lines) > 0 {
+		m.table.SelectLine(lines[len(lines)-1])
+	}
+	m.setStatusMessage("All filtered entries are reviewed", true)
+}
+
+func (m *Model) updateReviewProgress() {
+	counts := m.table.ReviewCounts()
+	parts := make([]string, 0, len(review.States))
+	for _, state := range review.States {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[state], state))
+	}
+	m.commandPanel.SetProgress(strings.Join(parts, " · "))
+}
+
+func (m *Model) closeReview() {
+	m.state = tableView
+	m.commandPanel.SetProgress("")
+}
+
+func renderReviewStatus(status review.Status) string {
+	var style lipgloss.Style
+	switch status.State {
+	case review.Accepted:
+		style = styles.OkLabel
+	case review.Rejected, review.Flagged:
+		style = styles.NoLabel
+	default:
+		style = styles.InfoLabel
+	}
+	text := "Review: " + string(status.State)
+	if status.Note != "" {
+		text += " — " + status.Note
+	}
+	return style.Render(text)
+}
+
+func (m *Model) renderReviewView() string {
+	detailStyle := styles.DetailPanel
+	innerWidth := m.width - 8
+	if innerWidth > 0 {
+		detailStyle = detailStyle.Copy().Width(innerWidth)
+	} else {
+		detailStyle = detailStyle.Copy()
+	}
+
+	entry := m.table.SelectedEntry()
+	if entry == nil {
+		return detailStyle.Render(styles.Text.Render("No entry selected."))
+	}
+
+	info := styles.InfoLabel.Render(fmt.Sprintf(
+		"Reviewing line %d — A accept, R reject, F flag with note, U unreviewed, N next unreviewed, J/K next/previous, ESC return",
+		entry.Line,
+	))
+	return detailStyle.Render(lipgloss.JoinVertical(
+		lipgloss.Left,
+		info,
+		renderReviewStatus(m.table.Review(entry.Line)),
+		m.detailViewport.View(),
+	))
+}
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"cutl/internal/dataset"
+	"cutl/internal/editor"
+	"cutl/internal/messages"
+	"fmt"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runSampleCommand replaces the filtered view with a random sample of it,
+// e.g. `:sample 200` or `:sample 50 --per .label --seed 7`. With --export
+// PATH the sample is written to a file instead and the view stays as is.
+// `:sample off` returns to the filter the sample was drawn from.
+func (m *Model) runSampleCommand(args string, options map[string]string) (tea.Cmd, error) {
+	if args == "off" {
+		if !m.table.IsCurrentFilterSample() {
+			return nil, fmt.Errorf("no sample shown")
+		}
+		filter := m.table.GetOriginalFilter()
+		return func() tea.Msg {
+			return messages.FilterQueryChanged{Query: filter}
+		}, nil
+	}
+
+	size, err := strconv.Atoi(args)
+	if err != nil || size <= 0 {
+		return nil, fmt.Errorf("usage: sample N [--per EXPR] [--seed N] [--export PATH] or sample off")
+	}
+	opts := dataset.SampleOptions{Size: size, Seed: time.Now().UnixNano()}
+	exportPath := ""
+	for name, value := range options {
+		switch name {
+		case "per":
+			if value == "" {
+				return nil, fmt.Errorf("--per needs a jq expression like .label")
+			}
+			opts.PerGroup = value
+		case "seed":
+			seed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --seed %q", value)
+			}
+			opts.Seed = seed
+		case "export":
+			if value == "" {
+				return nil, fmt.Errorf("--export needs a file path")
+			}
+			exportPath = value
+		default:
+			return nil, fmt.Errorf("unknown option --%s", name)
+		}
+	}
+
+	if m.table.IsCurrentFilterSample() {
+		// Draw a new sample from the original rows, not from the last sample.
+		sortColumn, ascending := m.table.SortState()
+		if err := m.table.RestoreView(m.table.GetOriginalFilter(), sortColumn, ascending); err != nil {
+			return nil, err
+		}
+	}
+	sample, err := dataset.Sample(m.table.FilteredEntries(), opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(sample) == 0 {
+		return nil, fmt.Errorf("no entries to sample")
+	}
+
+	if exportPath != "" {
+		if m.isInputPath(exportPath) {
+			return nil, fmt.Errorf("%s is the open file", exportPath)
+		}
+		header := m.sourceHeader
+		cmd := func() tea.Msg {
+			if err := editor.Write(exportPath, m.withoutVirtualFields(sample), header); err != nil {
+				return messages.InputFileWriteError{Error: err}
+			}
+			return messages.EntriesExported{Path: exportPath, Count: len(sample)}
+		}
+		return m.confirmOverwrite(cmd, exportPath), nil
+	}
+
+	lines := make([]int, len(sample))
+	for i, entry := range sample {
+		lines[i] = entry.Line
+	}
+	filter := m.table.SetSample(lines)
+	m.setStatusMessage(fmt.Sprintf("Showing a sample of %d entries (seed %d), :sample off to return", len(sample), opts.Seed), true)
+	return func() tea.Msg {
+		return messages.FilterQueryChanged{Query: filter}
+	}, nil
+}
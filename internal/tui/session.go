@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"cutl/internal/config"
+	"cutl/internal/dataset"
+	"cutl/internal/editor"
+	"fmt"
+)
+
+// SaveSession remembers the filter, sort, marks, tags, selected row and whether
+// the detail view was open, so the next start on the same file resumes there.
+func (m *Model) SaveSession() error {
+	entries := m.table.Entries()
+	// Merged and diff tables are not the file itself, so their state isn't
+	// saved for it.
+	if len(entries) == 0 || m.isMerged() || m.isDiff() {
+		return nil
+	}
+
+	filter := m.table.FilterQuery()
+	if m.table.IsCurrentFilterSpecial() {
+		// State filters depend on marks and statuses of this run.
+		filter = m.table.GetOriginalFilter()
+	}
+	sortColumn, ascending := m.table.SortState()
+	session := &config.Session{
+		Filter:        filter,
+		SortColumn:    sortColumn,
+		SortAscending: ascending,
+		Detail:        m.state == detailView || m.state == annotateView,
+	}
+
+	// Rows are saved by their key in the file as last loaded or written, so
+	// the session still matches the file when unsaved edits are discarded.
+	for _, line := range m.table.MarkedLines() {
+		if key, ok := m.diskKey(line); ok {
+			session.Marks = append(session.Marks, key)
+		}
+	}
+	for _, tag := range m.table.TagDefinitions() {
+		for _, line := range m.table.TaggedLines(tag.Name) {
+			key, ok := m.diskKey(line)
+			if !ok {
+				continue
+			}
+			if session.Tags == nil {
+				session.Tags = make(map[string][]string)
+			}
+			session.Tags[tag.Name] = append(session.Tags[tag.Name], key)
+		}
+	}
+
+	if entry := m.table.SelectedEntry(); entry != nil {
+		session.Selected, _ = m.diskKey(entry.Line)
+		session.SelectedLine = entry.Line
+	}
+
+	return m.config.UpdateSession(m.jsonlPath, session)
+}
+
+// restoreSession applies the session saved for the file once it is loaded.
+func (m *Model) restoreSession() {
+	fileConfig, _ := m.config.GetFileConfig(m.jsonlPath)
+	session := fileConfig.Session
+	if session == nil {
+		return
+	}
+
+	if err := m.table.RestoreView(session.Filter, session.SortColumn, session.SortAscending); err != nil {
+		m.setStatusErrorMessage(fmt.Sprintf("Saved filter dropped: %v", err), true)
+	}
+
+	lines := make(map[string]int, len(m.diskKeys))
+	for line, key := range m.diskKeys {
+		lines[key] = line
+	}
+	var marked []int
+	for _, key := range session.Marks {
+		if line, ok := lines[key]; ok {
+			marked = append(marked, line)
+		}
+	}
+	if len(marked) > 0 {
+		m.table.MarkLines(marked)
+	}
+	if session.Selected != "" {
+		selected, ok := lines[session.Selected]
+		if !ok {
+			selected = session.SelectedLine
+		}
+		m.table.SelectLine(selected)
+	}
+
+	if len(session.Tags) > 0 {
+		m.restoreTags(session.Tags, lines)
+	}
+
+	if session.Detail {
+		if entry := m.table.SelectedEntry(); entry != nil {
+			m.state = detailView
+			m.updateDetailContent(entry, true)
+		}
+	}
+}
+
+// restoreTags assigns saved tags to the rows whose keys still match.
+func (m *Model) restoreTags(saved map[string][]string, lines map[string]int) {
+	for _, tag := range m.table.TagDefinitions() {
+		var tagged []int
+		for _, key := range saved[tag.Name] {
+			if line, ok := lines[key]; ok {
+				tagged = append(tagged, line)
+			}
+		}
+		if len(tagged) > 0 {
+			m.table.SetTaggedLines(tag.Name, tagged)
+		}
+	}
+}
+
+// indexDiskRows keys the rows as they are in the file, once it is loaded or
+// written. Sessions and the review sidecar refer to rows by these keys.
+func (m *Model) indexDiskRows(entries []editor.Entry) {
+	entries = m.withoutVirtualFields(entries)
+	rows := make([]any, len(entries))
+	for i, entry := range entries {
+		rows[i] = entry.Data
+	}
+	keys := dataset.RowKeys(rows)
+	m.diskKeys = make(map[int]string, len(entries))
+	for i, entry := range entries {
+		m.diskKeys[entry.Line] = keys[i]
+	}
+}
+
+// diskKey returns the key of the row at line as it is in the file; false
+// for rows that are not in the file yet.
+func (m *Model) diskKey(line int) (string, bool) {
+	origin, ok := m.table.Origin(line)
+	if !ok {
+		return "", false
+	}
+	key, ok := m.diskKeys[origin]
+	return key, ok
+}
@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"cutl/internal/config"
+	"cutl/internal/editor"
+	"cutl/internal/spans"
+	"cutl/internal/tui/styles"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// writablePath matches the dotted field paths edits can write to, such as
+// .spans or .meta.entities; annotations are saved back to the spans path and
+// classification labels to the label path.
+var writablePath = regexp.MustCompile(`^(\.[^.\[\]"|() ]+)+$`)
+
+// spanColors are the backgrounds labels are hashed onto, so a label keeps
+// its color across entries and sessions.
+var spanColors = []lipgloss.Color{"25", "130", "28", "91", "124", "30", "94", "61", "160", "66"}
+
+func spanLabelColor(label string) lipgloss.Color {
+	h := fnv.New32a()
+	h.Write([]byte(label))
+	return spanColors[h.Sum32()%uint32(len(spanColors))]
+}
+
+// spanConfig returns the span settings of the file. Empty paths mean the
+// spans package defaults.
+func (m *Model) spanConfig() config.SpanConfig {
+	fileConfig, _ := m.config.GetFileConfig(m.jsonlPath)
+	if fileConfig.Spans == nil {
+		return config.SpanConfig{}
+	}
+	return *fileConfig.Spans
+}
+
+func (m *Model) spanPaths() (string, string) {
+	spanConfig := m.spanConfig()
+	return spanConfig.Text, spanConfig.Spans
+}
+
+func (m *Model) spanDocument(entry *editor.Entry) (*spans.Document, error) {
+	textPath, spansPath := m.spanPaths()
+	return spans.Extract(entry.Data, textPath, spansPath)
+}
+
+// runSpansCommand sets where the text and spans of the file live and which
+// labels annotation offers, e.g. `:spans --text .body --labels PER,ORG,LOC`.
+// `:spans reset` restores the defaults and a bare `:spans` shows the settings.
+func (m *Model) runSpansCommand(args string, options map[string]string) (tea.Cmd, error) {
+	spanConfig := m.spanConfig()
+
+	switch {
+	case args == "reset":
+		if err := m.config.UpdateSpans(m.jsonlPath, nil); err != nil {
+			return nil, fmt.Errorf("failed to save span settings: %w", err)
+		}
+		m.setStatusMessage("Span settings reset to defaults", true)
+		return nil, nil
+	case args != "":
+		return nil, fmt.Errorf("usage: spans [--text PATH] [--spans PATH] [--labels A,B,...] | spans reset")
+	case len(options) == 0:
+		m.setStatusNeutralMessage(describeSpanConfig(spanConfig), true)
+		return nil, nil
+	}
+
+	for name, value := range options {
+		switch name {
+		case "text", "spans":
+			if !strings.HasPrefix(value, ".") {
+				return nil, fmt.Errorf("--%s must be a path like .text", name)
+			}
+			if name == "text" {
+				spanConfig.Text = value
+			} else {
+				if !writablePath.MatchString(value) {
+					return nil, fmt.Errorf("--spans must be a field path like .spans or .meta.entities, annotations are written back to it")
+				}
+				spanConfig.Spans = value
+			}
+		case "labels":
+			spanConfig.Labels = nil
+			for _, label := range strings.Split(value, ",") {
+				if label = strings.TrimSpace(label); label != "" {
+					spanConfig.Labels = append(spanConfig.Labels, label)
+				}
+			}
+			if len(spanConfig.Labels) > 9 {
+				return nil, fmt.Errorf("at most 9 labels can be assigned to keys 1-9")
+			}
+		default:
+			return nil, fmt.Errorf("unknown option --%s", name)
+		}
+	}
+
+	if err := m.config.UpdateSpans(m.jsonlPath, &spanConfig); err != nil {
+		return nil, fmt.Errorf("failed to save span settings: %w", err)
+	}
+	m.setStatusMessage(describeSpanConfig(spanConfig), true)
+	return nil, nil
+}
+
+func describeSpanConfig(spanConfig config.SpanConfig) string {
+	description := fmt.Sprintf("Text: %s, spans: %s", describeTextPath(spanConfig.Text), describeSpansPath(spanConfig.Spans))
+	if len(spanConfig.Labels) > 0 {
+		description += ", labels: " + strings.Join(spanConfig.Labels, ", ")
+	}
+	return description
+}
+
+func describeTextPath(path string) string {
+	if path == "" {
+		return spans.DefaultTextPath
+	}
+	return path
+}
+
+func describeSpansPath(path string) string {
+	if path == "" {
+		return strings.Join(spans.DefaultSpanPaths, " or ")
+	}
+	return path
+}
+
+// renderSpanDocument shows the text with each span highlighted in its label
+// color and the label inline after it, displaCy style, followed by any
+// offset warnings.
+func renderSpanDocument(doc *spans.Document, width int) string {
+	runes := []rune(doc.Text)
+
+	var b strings.Builder
+	pos := 0
+	for _, s := range spans.Sorted(doc.Spans) {
+		// Overlapping and out-of-range spans are reported as warnings only.
+		if !s.Valid(len(runes)) || s.Start < pos {
+			continue
+		}
+		b.WriteString(renderLines(styles.Text, string(runes[pos:s.Start])))
+		b.WriteString(renderSpan(string(runes[s.Start:s.End]), s.Label))
+		pos = s.End
+	}
+	b.WriteString(renderLines(styles.Text, string(runes[pos:])))
+
+	text := b.String()
+	if width > 0 {
+		text = lipgloss.NewStyle().Width(width).Render(text)
+	}
+
+	header := styles.Label.Render(fmt.Sprintf("%s (%d spans)", doc.SpansPath, len(doc.Spans)))
+	lines := []string{header, text}
+	if warnings := spans.Check(doc.Text, doc.Spans); len(warnings) > 0 {
+		lines = append(lines, "")
+		for _, warning := range warnings {
+			lines = append(lines, styles.NoLabel.Render("⚠ "+warning))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderSpan(text, label string) string {
+	style := lipgloss.NewStyle().Background(spanLabelColor(label)).Foreground(lipgloss.Color("231"))
+	if label == "" {
+		return renderLines(style, text)
+	}
+	return renderLines(style, text) + style.Bold(true).Render(" "+label)
+}
+
+// renderLines styles each line on its own; rendering multi-line text at once
+// would pad every line to the width of the longest.
+func renderLines(style lipgloss.Style, text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = style.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
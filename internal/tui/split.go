@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"cutl/internal/dataset"
+	"cutl/internal/editor"
+	"cutl/internal/messages"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runSplitCommand partitions the filtered entries into one file per part,
+// e.g. `:split train=0.8,dev=0.1,test=0.1 --stratify .label --group .doc_id
+// --seed 42`. Without --seed a random one is used and reported.
+func (m *Model) runSplitCommand(args string, options map[string]string) (tea.Cmd, error) {
+	if args == "" {
+		args = "train=0.8,dev=0.1,test=0.1"
+	}
+	parts, err := dataset.ParseSplitParts(args)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := dataset.SplitOptions{Parts: parts, Seed: time.Now().UnixNano()}
+	dir := ""
+	for name, value := range options {
+		switch name {
+		case "stratify", "group":
+			if value == "" {
+				return nil, fmt.Errorf("--%s needs a jq expression like .label", name)
+			}
+			if name == "stratify" {
+				opts.Stratify = value
+			} else {
+				opts.Group = value
+			}
+		case "seed":
+			seed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --seed %q", value)
+			}
+			opts.Seed = seed
+		case "dir":
+			dir = value
+		default:
+			return nil, fmt.Errorf("unknown option --%s", name)
+		}
+	}
+
+	entries := m.table.FilteredEntries()
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no entries to split")
+	}
+	result, err := dataset.Split(entries, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	base := m.jsonlPath
+	if dir != "" {
+		base = filepath.Join(dir, filepath.Base(m.jsonlPath))
+	}
+	written := messages.SplitWritten{Seed: opts.Seed}
+	for i, part := range parts {
+		written.Parts = append(written.Parts, part.Name)
+		written.Paths = append(written.Paths, dataset.SplitPath(base, part.Name))
+		written.Counts = append(written.Counts, len(result[i]))
+		result[i] = m.withoutVirtualFields(result[i])
+	}
+	header := m.sourceHeader
+	cmd := func() tea.Msg {
+		for i, path := range written.Paths {
+			if err := editor.Write(path, result[i], header); err != nil {
+				return messages.InputFileWriteError{Error: err}
+			}
+		}
+		return written
+	}
+	return m.confirmOverwrite(cmd, written.Paths...), nil
+}
+
+func (m *Model) handleSplitWritten(msg messages.SplitWritten) {
+	parts := make([]string, len(msg.Parts))
+	for i, path := range msg.Paths {
+		parts[i] = fmt.Sprintf("%s (%d)", filepath.Base(path), msg.Counts[i])
+	}
+	m.setStatusMessage(fmt.Sprintf("Split into %s with seed %d", strings.Join(parts, ", "), msg.Seed), true)
+}
@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"cutl/internal/messages"
+	"cutl/internal/stats"
+	"cutl/internal/tui/styles"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// computeStatsCmd profiles the currently filtered entries in the background.
+// Results of older runs are dropped by comparing the generation.
+func (m *Model) computeStatsCmd() tea.Cmd {
+	m.statsGeneration++
+	m.loading = true
+	m.loadingText = "Profiling entries..."
+	generation := m.statsGeneration
+	entries := m.table.FilteredEntries()
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		return messages.StatsComputed{
+			Generation: generation,
+			Count:      len(entries),
+			Profiles:   stats.Profile(entries),
+		}
+	})
+}
+
+func (m *Model) handleStatsComputed(msg messages.StatsComputed) {
+	if msg.Generation != m.statsGeneration {
+		return
+	}
+	m.loading = false
+	m.statsCount = msg.Count
+	m.statsViewport.SetContent(renderProfiles(msg.Profiles))
+}
+
+func (m *Model) renderStatsView() string {
+	detailStyle := styles.DetailPanel
+	innerWidth := m.width - 8
+	if innerWidth > 0 {
+		detailStyle = detailStyle.Copy().Width(innerWidth)
+	} else {
+		detailStyle = detailStyle.Copy()
+	}
+
+	info := styles.InfoLabel.Render(fmt.Sprintf("Field profile of %d filtered entries — press S or ESC to return, F to filter", m.statsCount))
+
+	return detailStyle.Render(lipgloss.JoinVertical(lipgloss.Left, info, "", m.statsViewport.View()))
+}
+
+func renderProfiles(profiles []stats.FieldProfile) string {
+	if len(profiles) == 0 {
+		return styles.Text.Render("No fields found.")
+	}
+
+	var b strings.Builder
+	for i, p := range profiles {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(styles.Label.Render(p.Path))
+		b.WriteString(styles.InfoLabel.Render(fmt.Sprintf("  %.1f%% present (%d/%d)", p.Presence(), p.Present, p.Total)))
+		b.WriteString("\n")
+
+		b.WriteString(styles.Text.Render("  types: " + formatTypes(p.Types)))
+		distinct := fmt.Sprintf("%d", p.Distinct)
+		if p.DistinctTruncated {
+			distinct = fmt.Sprintf(">%d", p.Distinct)
+		}
+		b.WriteString(styles.Text.Render("   distinct: " + distinct))
+		b.WriteString("\n")
+
+		if len(p.TopValues) > 0 {
+			top := make([]string, 0, len(p.TopValues))
+			for _, v := range p.TopValues {
+				top = append(top, fmt.Sprintf("%s (%d)", truncateValue(v.Value, 30), v.Count))
+			}
+			b.WriteString(styles.Text.Render("  top: " + strings.Join(top, " · ")))
+			b.WriteString("\n")
+		}
+
+		if n := p.Numbers; n != nil {
+			b.WriteString(styles.Text.Render(fmt.Sprintf(
+				"  numbers: min %s  max %s  mean %s  p25 %s  p50 %s  p75 %s  p90 %s  p99 %s",
+				formatNumber(n.Min), formatNumber(n.Max), formatNumber(n.Mean),
+				formatNumber(n.Percentiles[25]), formatNumber(n.Percentiles[50]), formatNumber(n.Percentiles[75]),
+				formatNumber(n.Percentiles[90]), formatNumber(n.Percentiles[99]),
+			)))
+			b.WriteString("\n")
+		}
+
+		if h := p.StringLengths; h != nil {
+			b.WriteString(styles.Text.Render(fmt.Sprintf("  length: %d %s %d", h.Min, sparkline(h.Buckets), h.Max)))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func formatTypes(types map[string]int) string {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if types[names[i]] != types[names[j]] {
+			return types[names[i]] > types[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s %d", name, types[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatNumber(value float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", value), "0"), ".")
+}
+
+func sparkline(buckets []int) string {
+	maxCount := 0
+	for _, c := range buckets {
+		if c > maxCount {
+			maxCount = c
+		}
+	}
+	if maxCount == 0 {
+		return strings.Repeat(string(sparkRunes[0]), len(buckets))
+	}
+
+	var b strings.Builder
+	for _, c := range buckets {
+		if c == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		idx := c * (len(sparkRunes) - 1) / maxCount
+		b.WriteRune(sparkRunes[idx])
+	}
+	return b.String()
+}
+
+func truncateValue(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit-1]) + "…"
+}
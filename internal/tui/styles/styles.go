@@ -53,6 +53,8 @@ var (
 	CommandStatusError           = Label.Foreground(red).MarginTop(1)
 	CommandStatusNeutral         = Label.Foreground(midGray).MarginTop(1)
 
+	ListSelected = lipgloss.NewStyle().Foreground(cream).Background(subtleIndigo)
+
 	Text      = lipgloss.NewStyle().Foreground(normal)
 	InfoLabel = Label.Foreground(darkGray)
 	OkLabel   = Label.Foreground(green)
@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"cutl/internal/config"
+	"cutl/internal/editor"
+	"cutl/internal/messages"
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tagPalette colors new tags that were defined without --color.
+var tagPalette = []string{"red", "yellow", "green", "cyan", "blue", "magenta", "208", "141", "43"}
+
+var tagNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// runTagCommand toggles a tag on the marked or selected entries, defining it
+// on first use. The options scope an action to the entries carrying the tag:
+// `--filter`, `--mark`, `--delete`, `--edit`, `--export PATH` and `--drop`.
+func (m *Model) runTagCommand(args string, options map[string]string) (tea.Cmd, error) {
+	name := args
+	if name == "" {
+		return nil, fmt.Errorf("usage: tag NAME [--color C] [--filter|--mark|--delete|--edit|--export PATH|--drop]")
+	}
+	if !tagNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid tag name %q (use letters, digits, '-', '_' and '.')", name)
+	}
+
+	action := ""
+	for option := range options {
+		switch option {
+		case "color":
+		case "filter", "mark", "delete", "edit", "export", "drop":
+			if action != "" {
+				return nil, fmt.Errorf("--%s and --%s cannot be combined", action, option)
+			}
+			action = option
+		default:
+			return nil, fmt.Errorf("unknown option --%s", option)
+		}
+	}
+
+	defs := m.table.TagDefinitions()
+	index := -1
+	for i, tag := range defs {
+		if tag.Name == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 && action != "" {
+		return nil, fmt.Errorf("unknown tag %q", name)
+	}
+
+	color, recolor := options["color"]
+	if index < 0 || recolor {
+		if color == "" {
+			color = tagPalette[len(defs)%len(tagPalette)]
+		}
+		updated := append([]config.Tag{}, defs...)
+		if index < 0 {
+			updated = append(updated, config.Tag{Name: name, Color: color})
+		} else {
+			updated[index].Color = color
+		}
+		if err := m.saveTagDefinitions(updated); err != nil {
+			return nil, err
+		}
+		if index >= 0 && action == "" {
+			m.setStatusMessage(fmt.Sprintf("Tag %s is now %s", name, color), true)
+			return nil, nil
+		}
+	}
+
+	if action == "" {
+		return nil, m.toggleTag(name)
+	}
+
+	if action == "drop" {
+		return m.dropTag(name)
+	}
+	if action == "filter" {
+		var filter string
+		if m.table.IsCurrentFilterTag(name) {
+			filter = m.table.GetOriginalFilter()
+		} else {
+			filter = m.table.GenerateTagFilter(name)
+		}
+		return func() tea.Msg {
+			return messages.FilterQueryChanged{Query: filter}
+		}, nil
+	}
+
+	lines := m.table.TaggedLines(name)
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no entries tagged %s", name)
+	}
+
+	switch action {
+	case "mark":
+		m.table.ReplaceMarks(lines)
+		m.setStatusMessage(fmt.Sprintf("Marked %d entries tagged %s", len(lines), name), true)
+	case "delete":
+		removed := m.table.DeleteLines(lines)
+		m.setStatusMessage(fmt.Sprintf("Deleted %d entries tagged %s", removed, name), true)
+	case "edit":
+		m.openEditView(lines, false)
+	case "export":
+		path := options["export"]
+		if path == "" {
+			return nil, fmt.Errorf("--export needs a file path")
+		}
+		if m.isInputPath(path) {
+			return nil, fmt.Errorf("%s is the open file", path)
+		}
+		tagged := make(map[int]struct{}, len(lines))
+		for _, line := range lines {
+			tagged[line] = struct{}{}
+		}
+		var entries []editor.Entry
+		for _, entry := range m.table.Entries() {
+			if _, ok := tagged[entry.Line]; ok {
+				entries = append(entries, entry)
+			}
+		}
+		header := m.sourceHeader
+		cmd := func() tea.Msg {
+			if err := editor.Write(path, m.withoutVirtualFields(entries), header); err != nil {
+				return messages.InputFileWriteError{Error: err}
+			}
+			return messages.EntriesExported{Path: path, Count: len(entries)}
+		}
+		return m.confirmOverwrite(cmd, path), nil
+	}
+	return nil, nil
+}
+
+// runTagsCommand lists the defined tags with their hotkeys and counts.
+func (m *Model) runTagsCommand(args string, options map[string]string) (tea.Cmd, error) {
+	if args != "" || len(options) > 0 {
+		return nil, fmt.Errorf("usage: tags")
+	}
+	defs := m.table.TagDefinitions()
+	if len(defs) == 0 {
+		m.setStatusNeutralMessage("No tags defined (use :tag NAME)", true)
+		return nil, nil
+	}
+	parts := make([]string, 0, len(defs))
+	for i, tag := range defs {
+		part := fmt.Sprintf("%s (%s) %d", tag.Name, tag.Color, len(m.table.TaggedLines(tag.Name)))
+		if i < 9 {
+			part = fmt.Sprintf("alt+%d %s", i+1, part)
+		}
+		parts = append(parts, part)
+	}
+	m.setStatusNeutralMessage(strings.Join(parts, " · "), true)
+	return nil, nil
+}
+
+// toggleTagKey handles the alt+1..alt+9 hotkeys.
+func (m *Model) toggleTagKey(index int) {
+	defs := m.table.TagDefinitions()
+	if index >= len(defs) {
+		m.setStatusErrorMessage(fmt.Sprintf("No tag on alt+%d (define one with :tag NAME)", index+1), true)
+		return
+	}
+	if err := m.toggleTag(defs[index].Name); err != nil {
+		m.setStatusErrorMessage(err.Error(), true)
+	}
+}
+
+func (m *Model) toggleTag(name string) error {
+	count, added := m.table.ToggleTag(name)
+	if count == 0 {
+		return fmt.Errorf("no entry selected")
+	}
+	if added {
+		m.setStatusMessage(fmt.Sprintf("Tagged %d entries with %s", count, name), true)
+	} else {
+		m.setStatusMessage(fmt.Sprintf("Removed %s from %d entries", name, count), true)
+	}
+	return nil
+}
+
+func (m *Model) dropTag(name string) (tea.Cmd, error) {
+	var cmd tea.Cmd
+	if m.table.IsCurrentFilterTag(name) {
+		filter := m.table.GetOriginalFilter()
+		cmd = func() tea.Msg {
+			return messages.FilterQueryChanged{Query: filter}
+		}
+	}
+
+	var remaining []config.Tag
+	for _, tag := range m.table.TagDefinitions() {
+		if tag.Name != name {
+			remaining = append(remaining, tag)
+		}
+	}
+	if err := m.saveTagDefinitions(remaining); err != nil {
+		return nil, err
+	}
+	m.setStatusMessage(fmt.Sprintf("Dropped tag %s", name), true)
+	return cmd, nil
+}
+
+func (m *Model) saveTagDefinitions(tags []config.Tag) error {
+	if err := m.config.UpdateTags(m.jsonlPath, tags); err != nil {
+		return fmt.Errorf("failed to save tags: %w", err)
+	}
+	m.table.SetTagDefinitions(tags)
+	return nil
+}
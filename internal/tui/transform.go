@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runTransformCommand handles `transform EXPR [--rows filtered|marked|all]`:
+// every selected row is replaced by the output of the jq expression, e.g.
+// `.text |= ascii_downcase` or `del(.debug)`.
+func (m *Model) runTransformCommand(args string, options map[string]string) (tea.Cmd, error) {
+	if args == "" {
+		return nil, fmt.Errorf("usage: transform EXPR [--rows filtered|marked|all]")
+	}
+	for name := range options {
+		if name != "rows" {
+			return nil, fmt.Errorf("unknown option --%s", name)
+		}
+	}
+	entries, err := m.exportRows(options["rows"])
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no rows to transform")
+	}
+
+	lines := make([]int, len(entries))
+	for i, entry := range entries {
+		lines[i] = entry.Line
+	}
+	changed, err := m.table.TransformEntries(lines, args)
+	if err != nil {
+		return nil, fmt.Errorf("transform: %w", err)
+	}
+	m.setStatusMessage(fmt.Sprintf("Transformed %d of %d rows, press W to write", changed, len(lines)), true)
+	return nil, nil
+}
@@ -3,9 +3,15 @@ package tui
 import (
 	"context"
 	"cutl/internal/ai"
+	"cutl/internal/audit"
 	"cutl/internal/config"
+	"cutl/internal/dataset"
 	"cutl/internal/editor"
 	"cutl/internal/messages"
+	"cutl/internal/review"
+	"cutl/internal/script"
+	"cutl/internal/spans"
+	"cutl/internal/stats"
 	"cutl/internal/tui/commandpanel"
 	"cutl/internal/tui/cutable"
 	"cutl/internal/tui/styles"
@@ -17,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -32,8 +39,24 @@ const (
 	columnInputView
 	filterInputView
 	promptInputView
+	highlightInputView
+	groupInputView
+	commandInputView
+	reviewNoteInputView
 	detailView
 	editView
+	statsView
+	facetView
+	groupView
+	duplicatesView
+	inferView
+	annotateView
+	classifyView
+	reviewView
+	conflictView
+	writeDiffView
+	auditView
+	replaceView
 )
 
 type Model struct {
@@ -42,13 +65,21 @@ type Model struct {
 	state  viewState
 
 	jsonlPath               string
+	schemaPath              string
+	loadOptions             editor.LoadOptions
+	sourceHeader            []string
+	diskKeys                map[int]string // row keys of the file as loaded or written, by line
 	table                   cutable.Model
 	commandPanel            commandpanel.Model
 	detailViewport          viewport.Model
 	detailContent           string
 	detailLine              int
+	filterReturnState       viewState
 	confirmationActive      bool
 	pendingWriteCmd         tea.Cmd
+	writePreview            *dataset.WritePreview
+	writeDiffViewport       viewport.Model
+	writeReturnState        viewState
 	statusMessage           string
 	clearStatusOnNextAction bool
 
@@ -62,6 +93,85 @@ type Model struct {
 	editSingleMode  bool
 	editTargetLines []int
 
+	// Field profile
+	statsViewport   viewport.Model
+	statsGeneration int
+	statsCount      int
+
+	// Facets
+	facetGeneration int
+	facetColumn     int
+	facetValues     []stats.FacetValue
+	facetSelected   map[int]struct{}
+	facetCursor     int
+	facetOffset     int
+
+	// Group-by
+	groupGeneration int
+	groupInput      string
+	groupResult     *stats.GroupResult
+	groupTable      table.Model
+
+	// Duplicates
+	dupGeneration int
+	dupClusters   []dataset.Cluster
+	dupCursor     int
+	dupOffset     int
+
+	// Schema inference
+	inferViewport  viewport.Model
+	inferredSchema map[string]any
+	inferSavePath  string
+
+	// Span annotation
+	annotDoc    *spans.Document
+	annotLine   int
+	annotLabels []string
+	annotCursor int
+	annotAnchor int
+	annotOffset int
+
+	// Classification labeling
+	classify classifyState
+
+	// Review
+	reviewSidecar *review.Sidecar
+
+	// Merged inputs
+	mergeInputs    []string
+	mergeOptions   dataset.MergeOptions
+	mergeDropped   int
+	provenance     bool
+	conflicts      []dataset.Conflict
+	conflictCursor int
+
+	// Diff review
+	diffOldPath   string
+	diffKey       string
+	diffOutput    string
+	diffOld       []editor.Entry
+	diffUnchanged int
+
+	// Audit log
+	auditViewport viewport.Model
+	auditRecords  []audit.Record
+	pendingAudit  []cutable.Operation
+
+	// Script recording
+	recording  *script.Script
+	recordPath string
+
+	// Find and replace
+	replaceInputs     []textinput.Model
+	replaceRegex      bool
+	replaceIgnoreCase bool
+	replaceWord       bool
+	replaceTargets    []editor.Entry
+	replaceRows       string
+	replaceGeneration int
+	replacePreview    *messages.ReplacePreviewReady
+	replaceViewport   viewport.Model
+
 	// Configuration
 	config *config.Config
 
@@ -70,7 +180,7 @@ type Model struct {
 	lastAIPrompt string
 }
 
-func New(jsonlPath string) *Model {
+func New(jsonlPath string, schemaPath string) *Model {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -87,6 +197,7 @@ func New(jsonlPath string) *Model {
 		table:        cutable.New(),
 		commandPanel: commandpanel.New(),
 		jsonlPath:    jsonlPath,
+		schemaPath:   schemaPath,
 		state:        tableView,
 		config:       cfg,
 		spinner:      s,
@@ -102,10 +213,21 @@ func New(jsonlPath string) *Model {
 	}
 
 	m.detailViewport = viewport.New(0, 0)
+	m.statsViewport = viewport.New(0, 0)
+	m.inferViewport = viewport.New(0, 0)
+	m.writeDiffViewport = viewport.New(0, 0)
+	m.auditViewport = viewport.New(0, 0)
+	m.replaceViewport = viewport.New(0, 0)
 
 	return m
 }
 
+// SetLoadOptions sets how CSV and TSV input is converted. It must be called
+// before the program starts.
+func (m *Model) SetLoadOptions(options editor.LoadOptions) {
+	m.loadOptions = options
+}
+
 func (m *Model) Init() tea.Cmd {
 	// Start loading when initializing
 	m.loading = true
@@ -113,14 +235,30 @@ func (m *Model) Init() tea.Cmd {
 
 	return tea.Batch(
 		m.spinner.Tick,
+		m.loadSchemaCmd(),
 		func() tea.Msg {
 			// Try to load saved column configuration for this file
-			if fileConfig, exists := m.config.GetFileConfig(m.jsonlPath); exists && len(fileConfig.Columns) > 0 {
-				log.Debugf("Loaded saved columns for %s: %v", m.jsonlPath, fileConfig.Columns)
-				m.table.SetColumnQueries(fileConfig.Columns)
+			if fileConfig, exists := m.config.GetFileConfig(m.jsonlPath); exists {
+				if len(fileConfig.Columns) > 0 {
+					log.Debugf("Loaded saved columns for %s: %v", m.jsonlPath, fileConfig.Columns)
+					m.table.SetColumnQueries(fileConfig.Columns)
+				}
+				if len(fileConfig.Highlights) > 0 {
+					m.table.SetHighlightRules(fileConfig.Highlights)
+				}
+				if len(fileConfig.Tags) > 0 {
+					m.table.SetTagDefinitions(fileConfig.Tags)
+				}
+			}
+
+			if m.isMerged() {
+				return m.loadMergedCmd()
+			}
+			if m.isDiff() {
+				return m.loadDiffCmd()
 			}
 
-			jsonlContent, err := editor.LoadJSONL(m.jsonlPath)
+			jsonlContent, header, err := editor.Load(m.jsonlPath, m.loadOptions)
 
 			if err != nil {
 				log.Errorf("Failed to load JSONL file %s: %v", m.jsonlPath, err)
@@ -131,6 +269,7 @@ func (m *Model) Init() tea.Cmd {
 				log.Debugf("JSONL file %s loaded successfully.", m.jsonlPath)
 				return messages.InputFileLoaded{
 					Content: jsonlContent,
+					Header:  header,
 				}
 			}
 		},
@@ -156,9 +295,13 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		if m.confirmationActive {
 			skipTableUpdate = true
+			if m.handleWritePreviewKey(msg) {
+				break
+			}
 			switch key {
 			case "y", "Y", "enter":
 				m.confirmationActive = false
+				m.closeWritePreview()
 				if m.pendingWriteCmd != nil {
 					m.setStatusMessage("Saving…", false)
 					cmds = append(cmds, m.pendingWriteCmd)
@@ -166,6 +309,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			case "n", "N", "esc":
 				m.confirmationActive = false
+				m.closeWritePreview()
 				m.pendingWriteCmd = nil
 				m.setStatusMessage("Save cancelled", true)
 			}
@@ -173,6 +317,15 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		switch m.state {
 		case tableView:
+			if m.table.VisualActive() {
+				var passKey bool
+				passKey, cmd = m.handleVisualKey(key)
+				if cmd != nil {
+					return m, cmd
+				}
+				skipTableUpdate = !passKey
+				break
+			}
 			switch key {
 			case "c":
 				m.state = columnInputView
@@ -180,8 +333,27 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			case "f":
 				m.state = filterInputView
+				m.filterReturnState = tableView
 				m.commandPanel.ActivateFilter(m.table.FilterQuery())
 				return m, nil
+			case "s", "S":
+				m.state = statsView
+				m.statsViewport.GotoTop()
+				return m, m.computeStatsCmd()
+			case "t", "T":
+				return m, m.openFacets()
+			case ":":
+				m.state = commandInputView
+				m.commandPanel.ActivateCommand()
+				return m, nil
+			case "a", "A":
+				m.state = groupInputView
+				m.commandPanel.ActivateGroupBy(m.groupInput)
+				return m, nil
+			case "h", "H":
+				m.state = highlightInputView
+				m.commandPanel.ActivateHighlights(cutable.FormatHighlightRules(m.table.HighlightRules()))
+				return m, nil
 			case "p", "P":
 				if m.aiClient == nil {
 					m.setStatusErrorMessage("AI filter unavailable (set OPENAI_API_KEY)", true)
@@ -229,7 +401,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			case "w", "W":
 				skipTableUpdate = true
-				m.requestWriteConfirmation()
+				cmds = append(cmds, m.requestWriteConfirmation())
 			case "esc":
 				if m.table.MarkedCount() > 0 {
 					skipTableUpdate = true
@@ -242,6 +414,25 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else {
 					m.setStatusMessage("All visible entries already marked", true)
 				}
+			case "ctrl+v":
+				skipTableUpdate = true
+				m.startVisual()
+			case "+", "-":
+				skipTableUpdate = true
+				if m.isDiff() {
+					m.decideSelectedChange(map[string]string{"+": diffAccepted, "-": diffRejected}[key])
+				}
+			case "i", "I":
+				skipTableUpdate = true
+				marked := m.table.InvertVisibleMarks()
+				m.setStatusMessage(fmt.Sprintf("Inverted marks of visible entries (%d marked)", marked), true)
+			case "left", "right":
+				skipTableUpdate = true
+				if key == "left" {
+					m.table.MoveColumnCursor(-1)
+				} else {
+					m.table.MoveColumnCursor(1)
+				}
 			case "1", "2", "3", "4", "5", "6", "7", "8", "9":
 				skipTableUpdate = true
 				if columnIndex := int(key[0] - '1'); columnIndex < len(m.table.ColumnQueries()) {
@@ -249,6 +440,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						return messages.SortByColumn{ColumnIndex: columnIndex}
 					}
 				}
+			case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9":
+				skipTableUpdate = true
+				m.toggleTagKey(int(key[len(key)-1] - '1'))
 			case "v", "V":
 				skipTableUpdate = true
 				m.setStatusNeutralMessage(version.GetFullVersion(), true)
@@ -285,10 +479,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case filterInputView:
 			switch key {
 			case "esc":
-				m.state = tableView
+				m.state = m.filterReturnState
 				m.commandPanel.Deactivate()
 			case "enter":
-				m.state = tableView
+				m.state = m.filterReturnState
 				m.commandPanel.Deactivate()
 				rawQuery := m.commandPanel.Value()
 
@@ -302,6 +496,73 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
+		case highlightInputView:
+			switch key {
+			case "esc":
+				m.state = tableView
+				m.commandPanel.Deactivate()
+			case "enter":
+				rules, err := cutable.ParseHighlightRules(m.commandPanel.Value())
+				if err != nil {
+					m.setStatusErrorMessage(err.Error(), true)
+					break
+				}
+				m.state = tableView
+				m.commandPanel.Deactivate()
+
+				if err := m.config.UpdateHighlights(m.jsonlPath, rules); err != nil {
+					log.Warnf("Failed to save highlight rules: %v", err)
+				}
+
+				m.setStatusMessage(fmt.Sprintf("%d highlight rules active", len(rules)), true)
+				return m, func() tea.Msg {
+					return messages.HighlightRulesChanged{Rules: rules}
+				}
+			}
+		case groupInputView:
+			switch key {
+			case "esc":
+				m.state = tableView
+				m.commandPanel.Deactivate()
+			case "enter":
+				groupExpr, aggregates, err := parseGroupBy(m.commandPanel.Value())
+				if err != nil {
+					m.setStatusErrorMessage(err.Error(), true)
+					break
+				}
+				m.groupInput = m.commandPanel.Value()
+				m.state = tableView
+				m.commandPanel.Deactivate()
+				return m, m.computeGroupByCmd(groupExpr, aggregates)
+			}
+		case commandInputView:
+			switch key {
+			case "esc":
+				m.state = tableView
+				m.commandPanel.Deactivate()
+			case "enter":
+				m.state = tableView
+				m.commandPanel.Deactivate()
+				cmd, err := m.runCommand(m.commandPanel.Value())
+				if err != nil {
+					m.setStatusErrorMessage(err.Error(), true)
+					break
+				}
+				return m, cmd
+			}
+		case reviewNoteInputView:
+			switch key {
+			case "esc":
+				m.state = reviewView
+				m.commandPanel.Deactivate()
+			case "enter":
+				m.state = reviewView
+				m.commandPanel.Deactivate()
+				note := strings.TrimSpace(m.commandPanel.Value())
+				if err := m.setReview(review.Status{State: review.Flagged, Note: note}); err != nil {
+					m.setStatusErrorMessage(err.Error(), true)
+				}
+			}
 		case promptInputView:
 			switch key {
 			case "esc":
@@ -342,6 +603,11 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "e", "E":
 				m.initializeEditView()
 				return m, nil
+			case "n", "N":
+				if err := m.openAnnotation(); err != nil {
+					m.setStatusErrorMessage(err.Error(), true)
+				}
+				return m, nil
 			case " ":
 				m.table.ToggleMarkSelectedAndMoveDown()
 			case "m", "M":
@@ -374,7 +640,12 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.updateDetailContent(m.table.SelectedEntry(), true)
 				}
 			case "w", "W":
-				m.requestWriteConfirmation()
+				cmds = append(cmds, m.requestWriteConfirmation())
+			case "+", "-":
+				if m.isDiff() {
+					m.decideSelectedChange(map[string]string{"+": diffAccepted, "-": diffRejected}[key])
+					m.updateDetailContent(m.table.SelectedEntry(), true)
+				}
 			case "ctrl+a":
 				markedCount := m.table.MarkAllVisible()
 				if markedCount > 0 {
@@ -388,11 +659,232 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						return messages.SortByColumn{ColumnIndex: columnIndex}
 					})
 				}
+			case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9":
+				m.toggleTagKey(int(key[len(key)-1] - '1'))
 			case "v", "V":
 				m.setStatusNeutralMessage(version.GetFullVersion(), true)
 			case "ctrl+c", "q":
 				return m, tea.Quit
 			}
+		case statsView:
+			skipTableUpdate = true
+			switch key {
+			case "esc", "s", "S":
+				m.state = tableView
+				return m, nil
+			case "f", "F":
+				m.state = filterInputView
+				m.filterReturnState = statsView
+				m.commandPanel.ActivateFilter(m.table.FilterQuery())
+				return m, nil
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			}
+		case facetView:
+			skipTableUpdate = true
+			switch key {
+			case "esc", "t", "T":
+				m.state = tableView
+				return m, nil
+			case "up", "k":
+				m.moveFacetCursor(-1)
+			case "down", "j":
+				m.moveFacetCursor(1)
+			case "pgup":
+				m.moveFacetCursor(-10)
+			case "pgdown":
+				m.moveFacetCursor(10)
+			case "left", "h", "shift+tab":
+				cmds = append(cmds, m.cycleFacetColumn(-1))
+			case "right", "l", "tab":
+				cmds = append(cmds, m.cycleFacetColumn(1))
+			case " ":
+				m.toggleFacetSelection()
+			case "enter":
+				cmds = append(cmds, m.facetFilterCmd())
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			}
+		case groupView:
+			skipTableUpdate = true
+			switch key {
+			case "esc", "a", "A":
+				m.state = tableView
+				return m, nil
+			case "enter":
+				return m, m.groupDrillDownCmd()
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			default:
+				m.groupTable, cmd = m.groupTable.Update(msg)
+				cmds = append(cmds, cmd)
+			}
+		case conflictView:
+			skipTableUpdate = true
+			if key == "ctrl+c" || key == "q" {
+				return m, tea.Quit
+			}
+			m.handleConflictKey(key)
+		case duplicatesView:
+			skipTableUpdate = true
+			switch key {
+			case "esc":
+				m.state = tableView
+				return m, nil
+			case "up", "k":
+				m.moveDupCursor(-1)
+			case "down", "j":
+				m.moveDupCursor(1)
+			case "pgup":
+				m.moveDupCursor(-10)
+			case "pgdown":
+				m.moveDupCursor(10)
+			case " ":
+				if m.dupCursor < len(m.dupClusters) {
+					m.markDuplicates(m.dupClusters[m.dupCursor : m.dupCursor+1])
+					m.moveDupCursor(1)
+				}
+			case "enter":
+				m.markDuplicates(m.dupClusters)
+				m.state = tableView
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			}
+		case annotateView:
+			skipTableUpdate = true
+			runes := []rune(m.annotDoc.Text)
+			var err error
+			switch key {
+			case "esc":
+				if m.annotAnchor >= 0 {
+					m.annotAnchor = -1
+					return m, nil
+				}
+				m.state = detailView
+				m.updateDetailContent(m.table.SelectedEntry(), false)
+				return m, nil
+			case "left", "h":
+				m.moveAnnotCursor(m.annotCursor-1, false)
+			case "right", "l":
+				m.moveAnnotCursor(m.annotCursor+1, false)
+			case "shift+left", "H":
+				m.moveAnnotCursor(m.annotCursor-1, true)
+			case "shift+right", "L":
+				m.moveAnnotCursor(m.annotCursor+1, true)
+			case "ctrl+left", "b", "B":
+				m.moveAnnotCursor(wordStart(runes, m.annotCursor), false)
+			case "ctrl+right", "w", "W":
+				m.moveAnnotCursor(wordEnd(runes, m.annotCursor), false)
+			case "home", "0":
+				m.moveAnnotCursor(0, false)
+			case "end", "$":
+				m.moveAnnotCursor(len(runes)-1, false)
+			case "tab":
+				m.jumpAnnotSpan(1)
+			case "shift+tab":
+				m.jumpAnnotSpan(-1)
+			case "v", "V":
+				m.toggleAnnotSelection()
+			case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+				err = m.assignAnnotLabel(int(key[0] - '1'))
+			case "x", "X", "delete", "backspace":
+				err = m.removeAnnotSpans()
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			}
+			if err != nil {
+				m.setStatusErrorMessage(err.Error(), true)
+			}
+		case reviewView:
+			skipTableUpdate = true
+			var err error
+			switch key {
+			case "esc":
+				m.closeReview()
+				return m, nil
+			case "a", "A":
+				err = m.setReview(review.Status{State: review.Accepted})
+			case "r", "R":
+				err = m.setReview(review.Status{State: review.Rejected})
+			case "f", "F":
+				if entry := m.table.SelectedEntry(); entry != nil {
+					m.state = reviewNoteInputView
+					m.commandPanel.ActivateNote(m.table.Review(entry.Line).Note)
+				}
+				return m, nil
+			case "u", "U":
+				err = m.setReview(review.Status{State: review.Unreviewed})
+			case "n", "N", "tab":
+				m.nextUnreviewed()
+			case "down", "j", "J":
+				m.moveSelection(1)
+				m.updateDetailContent(m.table.SelectedEntry(), true)
+			case "up", "k", "K":
+				m.moveSelection(-1)
+				m.updateDetailContent(m.table.SelectedEntry(), true)
+			case "pgdown":
+				m.detailViewport.PageDown()
+			case "pgup":
+				m.detailViewport.PageUp()
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			}
+			if err != nil {
+				m.setStatusErrorMessage(err.Error(), true)
+			}
+		case classifyView:
+			skipTableUpdate = true
+			var err error
+			switch key {
+			case "esc":
+				m.closeClassify()
+				return m, nil
+			case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+				err = m.assignClassLabel(int(key[0] - '1'))
+			case "0", "backspace":
+				err = m.setClassLabel("", false)
+			case "down", "j", "J":
+				m.moveSelection(1)
+			case "up", "k", "K":
+				m.moveSelection(-1)
+			case "tab":
+				if entry := m.table.SelectedEntry(); entry != nil {
+					m.advanceToUnlabeled(m.filteredLines(), entry.Line)
+				}
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			}
+			if err != nil {
+				m.setStatusErrorMessage(err.Error(), true)
+			}
+		case auditView:
+			skipTableUpdate = true
+			switch key {
+			case "esc":
+				m.state = tableView
+				return m, nil
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			}
+		case replaceView:
+			skipTableUpdate = true
+			if key == "ctrl+c" {
+				return m, tea.Quit
+			}
+			return m, m.handleReplaceKey(msg)
+		case inferView:
+			skipTableUpdate = true
+			switch key {
+			case "esc":
+				m.state = tableView
+				return m, nil
+			case "w", "W":
+				return m, m.saveInferredSchema(false)
+			case "a", "A":
+				return m, m.saveInferredSchema(true)
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			}
 		case editView:
 			skipTableUpdate = true
 			switch key {
@@ -420,11 +912,25 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 	case messages.InputFileWritten:
 		log.Debugf("Saved %d entries to %s", msg.Count, msg.Path)
+		if msg.Path == m.jsonlPath {
+			m.flushAudit(msg.Path, msg.Path)
+			m.table.ResetOrigins()
+			m.indexDiskRows(m.table.Entries())
+			if err := m.saveReviewSidecar(review.SidecarPath(msg.Path)); err != nil {
+				log.Warnf("Failed to save reviews: %v", err)
+			}
+		}
 		filename := filepath.Base(msg.Path)
 		if filename == "" {
 			filename = msg.Path
 		}
 		m.setStatusMessage(fmt.Sprintf("Saved: %s", filename), true)
+	case messages.EntriesExported:
+		m.handleEntriesExported(msg)
+	case messages.InputFileSavedAs:
+		m.handleSavedAs(msg)
+	case messages.SplitWritten:
+		m.handleSplitWritten(msg)
 	case messages.InputFileWriteError:
 		log.Errorf("Failed to write JSONL file %s: %v", m.jsonlPath, msg.Error)
 		m.setStatusErrorMessage(fmt.Sprintf("Save failed: %v", msg.Error), true)
@@ -433,6 +939,31 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.setStatusErrorMessage(fmt.Sprintf("Load failed: %v", msg.Error), true)
 		// Stop loading spinner on file load error
 		m.loading = false
+	case messages.SchemaLoaded:
+		m.table.SetValidator(msg.Validator)
+		cmds = append(cmds, m.validateEntriesCmd(m.table.Entries()))
+	case messages.SchemaLoadError:
+		log.Errorf("Failed to load schema: %v", msg.Error)
+		m.setStatusErrorMessage(fmt.Sprintf("Schema failed: %v", msg.Error), true)
+	case messages.ValidationCompleted:
+		m.table.SetViolations(msg.Violations)
+		if len(msg.Violations) > 0 {
+			m.setStatusErrorMessage(fmt.Sprintf("%d entries violate the schema (:invalid to show them)", len(msg.Violations)), true)
+		}
+	case messages.RestoreSession:
+		m.restoreSession()
+	case messages.ReviewsLoaded:
+		m.handleReviewsLoaded(msg)
+	case messages.SchemaInferred:
+		m.handleSchemaInferred(msg)
+	case messages.DuplicatesFound:
+		m.handleDuplicatesFound(msg)
+	case messages.GroupByComputed:
+		m.handleGroupByComputed(msg)
+	case messages.FacetComputed:
+		m.handleFacetComputed(msg)
+	case messages.StatsComputed:
+		m.handleStatsComputed(msg)
 	case messages.EditApplied:
 		log.Debugf("EditApplied message received")
 		if msg.SingleMode {
@@ -483,15 +1014,49 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.setStatusNeutralMessage(fmt.Sprintf("%s", filename), false)
 		// Stop loading spinner when file is loaded
 		m.loading = false
+		m.sourceHeader = msg.Header
+		m.pendingAudit = nil
+		m.table.SetJournaling(m.journalingWanted())
+		// The table takes the rows after this switch; its origins restart
+		// here already so the loaded rows are looked up by their own lines.
+		m.table.ResetOrigins()
+		m.indexDiskRows(msg.Content)
+		cmds = append(cmds, m.validateEntriesCmd(msg.Content), m.loadReviewsCmd(msg.Content))
+		if m.isMerged() {
+			m.showMergeSummary(len(msg.Content))
+		} else if m.isDiff() {
+			m.showDiffSummary(msg.Content)
+		} else {
+			cmds = append(cmds, func() tea.Msg { return messages.RestoreSession{} })
+		}
+	case messages.InputFilesMerged:
+		cmds = append(cmds, m.handleInputFilesMerged(msg))
+	case messages.WritePreviewReady:
+		m.handleWritePreviewReady(msg)
+	case messages.DiffLoaded:
+		cmds = append(cmds, m.handleDiffLoaded(msg))
+	case messages.AuditLogLoaded:
+		m.handleAuditLogLoaded(msg)
+	case messages.ReplacePreviewReady:
+		m.handleReplacePreviewReady(msg)
+	case messages.InferredSchemaSaved:
+		cmds = append(cmds, m.handleInferredSchemaSaved(msg))
 	}
 
-	if !skipTableUpdate && (m.state == tableView || m.state == detailView) {
+	_, isKey := msg.(tea.KeyMsg)
+	if !skipTableUpdate && (!isKey || m.state == tableView || m.state == detailView) {
 		m.table, cmd = m.table.Update(msg)
 		cmds = append(cmds, cmd)
+		if m.table.VisualActive() {
+			m.updateVisualProgress()
+		}
 
 		// Stop loading spinner after filter operations complete
 		if _, ok := msg.(messages.FilterQueryChanged); ok {
 			m.loading = false
+			if m.state == statsView {
+				cmds = append(cmds, m.computeStatsCmd())
+			}
 		}
 	}
 
@@ -502,6 +1067,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Keys reach the replace inputs through handleReplaceKey.
+	if _, isKey := msg.(tea.KeyMsg); m.state == replaceView && !isKey {
+		for i := range m.replaceInputs {
+			m.replaceInputs[i], cmd = m.replaceInputs[i].Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	}
+
 	if m.state == detailView {
 		var vCmd tea.Cmd
 		m.detailViewport, vCmd = m.detailViewport.Update(msg)
@@ -511,6 +1084,30 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.updateDetailContent(m.table.SelectedEntry(), false)
 	}
+
+	if m.state == statsView {
+		var vCmd tea.Cmd
+		m.statsViewport, vCmd = m.statsViewport.Update(msg)
+		if vCmd != nil {
+			cmds = append(cmds, vCmd)
+		}
+	}
+
+	if m.state == inferView {
+		var vCmd tea.Cmd
+		m.inferViewport, vCmd = m.inferViewport.Update(msg)
+		if vCmd != nil {
+			cmds = append(cmds, vCmd)
+		}
+	}
+
+	if m.state == auditView {
+		var vCmd tea.Cmd
+		m.auditViewport, vCmd = m.auditViewport.Update(msg)
+		if vCmd != nil {
+			cmds = append(cmds, vCmd)
+		}
+	}
 	m.commandPanel, cmd = m.commandPanel.Update(msg)
 	cmds = append(cmds, cmd)
 
@@ -555,6 +1152,16 @@ func (m *Model) View() string {
 		viewportHeight = 1
 	}
 	m.detailViewport.Height = viewportHeight
+	m.statsViewport.Width = viewportWidth
+	m.statsViewport.Height = viewportHeight
+	m.inferViewport.Width = viewportWidth
+	m.inferViewport.Height = viewportHeight
+	m.writeDiffViewport.Width = viewportWidth
+	m.writeDiffViewport.Height = viewportHeight
+	m.auditViewport.Width = viewportWidth
+	m.auditViewport.Height = viewportHeight
+	m.replaceViewport.Width = viewportWidth
+	m.replaceViewport.Height = viewportHeight
 
 	if m.loading {
 		// Show loading spinner with message
@@ -569,6 +1176,30 @@ func (m *Model) View() string {
 		sections = append(sections, m.renderDetailView())
 	} else if m.state == editView {
 		sections = append(sections, m.renderEditView())
+	} else if m.state == statsView {
+		sections = append(sections, m.renderStatsView())
+	} else if m.state == facetView {
+		sections = append(sections, m.renderFacetView(tableHeight))
+	} else if m.state == groupView {
+		sections = append(sections, m.renderGroupView(tableHeight))
+	} else if m.state == duplicatesView {
+		sections = append(sections, m.renderDuplicatesView(tableHeight))
+	} else if m.state == inferView {
+		sections = append(sections, m.renderInferView())
+	} else if m.state == annotateView {
+		sections = append(sections, m.renderAnnotateView())
+	} else if m.state == reviewView {
+		sections = append(sections, m.renderReviewView())
+	} else if m.state == classifyView {
+		sections = append(sections, m.renderClassifyView(tableHeight))
+	} else if m.state == writeDiffView {
+		sections = append(sections, m.renderWriteDiffView())
+	} else if m.state == conflictView {
+		sections = append(sections, m.renderConflictView(tableHeight))
+	} else if m.state == auditView {
+		sections = append(sections, m.renderAuditView())
+	} else if m.state == replaceView {
+		sections = append(sections, m.renderReplaceView())
 	} else {
 		sections = append(sections, m.table.View())
 	}
@@ -592,7 +1223,11 @@ func (m *Model) renderDetailView() string {
 		return detailStyle.Render(styles.Text.Render("No entry selected."))
 	}
 
-	info := styles.InfoLabel.Render(fmt.Sprintf("Line %d — press D or ESC to return to the table", entry.Line))
+	hint := "press D or ESC to return to the table"
+	if doc, err := m.spanDocument(entry); err == nil && doc != nil {
+		hint += ", N to annotate spans"
+	}
+	info := styles.InfoLabel.Render(fmt.Sprintf("Line %d — %s", entry.Line, hint))
 	viewportView := m.detailViewport.View()
 
 	return detailStyle.Render(lipgloss.JoinVertical(lipgloss.Left, info, "", viewportView))
@@ -680,6 +1315,22 @@ func (m *Model) updateDetailContent(entry *editor.Entry, reset bool) {
 		} else {
 			content = styles.Text.Copy().Render(string(formatted))
 		}
+		if doc, err := m.spanDocument(entry); err != nil {
+			content = styles.NoLabel.Render(fmt.Sprintf("⚠ %v", err)) + "\n\n" + content
+		} else if doc != nil {
+			content = renderSpanDocument(doc, m.detailViewport.Width) + "\n\n" + content
+		}
+		if status := m.table.Review(entry.Line); status.State != review.Unreviewed && m.state == detailView {
+			content = renderReviewStatus(status) + "\n\n" + content
+		}
+		if violations := m.table.Violations(entry.Line); len(violations) > 0 {
+			content = renderViolations(violations) + "\n\n" + content
+		}
+		if m.isDiff() {
+			if fields := m.renderDiffFields(entry); fields != "" {
+				content = fields + "\n\n" + content
+			}
+		}
 		line = entry.Line
 	}
 
@@ -695,6 +1346,35 @@ func (m *Model) updateDetailContent(entry *editor.Entry, reset bool) {
 	m.detailLine = line
 }
 
+// filteredLines returns the lines of the filtered entries in display order.
+func (m *Model) filteredLines() []int {
+	entries := m.table.FilteredEntries()
+	lines := make([]int, len(entries))
+	for i, entry := range entries {
+		lines[i] = entry.Line
+	}
+	return lines
+}
+
+// moveSelection moves the table cursor by delta filtered rows, for views
+// that show one entry at a time.
+func (m *Model) moveSelection(delta int) {
+	entry := m.table.SelectedEntry()
+	if entry == nil {
+		return
+	}
+	lines := m.filteredLines()
+	for i, line := range lines {
+		if line == entry.Line {
+			next := i + delta
+			if next >= 0 && next < len(lines) {
+				m.table.SelectLine(lines[next])
+			}
+			return
+		}
+	}
+}
+
 func (m *Model) setStatusMessage(message string, clearOnNext bool) {
 	m.statusMessage = message
 	m.clearStatusOnNextAction = clearOnNext
@@ -722,21 +1402,24 @@ func (m *Model) clearStatusMessage() {
 	m.commandPanel.SetStatus("")
 }
 
-func (m *Model) requestWriteConfirmation() {
-	m.pendingWriteCmd = m.writeTableToFileCmd()
-	filename := filepath.Base(m.jsonlPath)
-	if filename == "" {
-		filename = m.jsonlPath
+// requestWriteConfirmation prepares writing the table back to its file. The
+// confirmation shows what will change once the preview is computed.
+func (m *Model) requestWriteConfirmation() tea.Cmd {
+	if m.isDiff() {
+		m.requestDiffWriteConfirmation()
+		return nil
+	}
+	if m.isMerged() {
+		m.setStatusErrorMessage(fmt.Sprintf("This table merges %d files, write it with :saveas PATH", len(m.mergeInputs)), true)
+		return nil
 	}
-	prompt := fmt.Sprintf("Write changes to %s? (y/N)", filename)
-	m.confirmationActive = true
-	m.setStatusMessage(prompt, false)
+	return m.previewWriteCmd()
 }
 
 func (m *Model) writeTableToFileCmd() tea.Cmd {
-	entries := m.table.Entries()
+	entries := m.withoutVirtualFields(m.table.Entries())
 	return func() tea.Msg {
-		if err := editor.WriteJSONL(m.jsonlPath, entries); err != nil {
+		if err := editor.Write(m.jsonlPath, entries, m.sourceHeader); err != nil {
 			return messages.InputFileWriteError{Error: err}
 		}
 		return messages.InputFileWritten{Path: m.jsonlPath, Count: len(entries)}
@@ -744,25 +1427,25 @@ func (m *Model) writeTableToFileCmd() tea.Cmd {
 }
 
 func (m *Model) initializeEditView() {
-	columns := m.table.ColumnQueries()
-	if len(columns) == 0 {
-		return
-	}
-
 	markedCount := m.table.MarkedCount()
 	if markedCount > 0 {
 		// Multi-line edit mode
-		m.editSingleMode = false
-		m.editTargetLines = m.getMarkedLines()
-	} else {
+		m.openEditView(m.getMarkedLines(), false)
+	} else if entry := m.table.SelectedEntry(); entry != nil {
 		// Single line edit mode
-		m.editSingleMode = true
-		if entry := m.table.SelectedEntry(); entry != nil {
-			m.editTargetLines = []int{entry.Line}
-		} else {
-			return
-		}
+		m.openEditView([]int{entry.Line}, true)
+	}
+}
+
+// openEditView edits the given lines; a single line is pre-filled from the
+// selected entry.
+func (m *Model) openEditView(lines []int, singleMode bool) {
+	columns := m.table.ColumnQueries()
+	if len(columns) == 0 {
+		return
 	}
+	m.editSingleMode = singleMode
+	m.editTargetLines = lines
 
 	// Create text inputs for each column
 	m.editInputs = make([]textinput.Model, len(columns))
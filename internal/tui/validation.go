@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"cutl/internal/editor"
+	"cutl/internal/messages"
+	"cutl/internal/schema"
+	"cutl/internal/tui/styles"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+// resolveSchemaPath prefers the --schema flag and remembers it for the file,
+// otherwise it falls back to the schema attached in the config. Schema paths
+// are stored absolute so they work from any working directory.
+func (m *Model) resolveSchemaPath() string {
+	if m.schemaPath != "" {
+		absPath, err := filepath.Abs(m.schemaPath)
+		if err != nil {
+			absPath = m.schemaPath
+		}
+		if fileConfig, _ := m.config.GetFileConfig(m.jsonlPath); fileConfig.Schema != absPath {
+			if err := m.config.UpdateSchema(m.jsonlPath, absPath); err != nil {
+				log.Warnf("Failed to save schema path: %v", err)
+			}
+		}
+		return absPath
+	}
+	if fileConfig, exists := m.config.GetFileConfig(m.jsonlPath); exists {
+		return fileConfig.Schema
+	}
+	return ""
+}
+
+func (m *Model) loadSchemaCmd() tea.Cmd {
+	schemaPath := m.resolveSchemaPath()
+	if schemaPath == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		validator, err := schema.Load(schemaPath)
+		if err != nil {
+			return messages.SchemaLoadError{Error: err}
+		}
+		return messages.SchemaLoaded{Validator: validator}
+	}
+}
+
+// validateEntriesCmd validates entries in the background. It is started once
+// both the file and the schema are loaded; edits are revalidated in place.
+func (m *Model) validateEntriesCmd(entries []editor.Entry) tea.Cmd {
+	validator := m.table.Validator()
+	if validator == nil || len(entries) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		return messages.ValidationCompleted{Violations: validator.ValidateEntries(entries)}
+	}
+}
+
+// runInvalidCommand toggles the filter that only shows entries violating
+// the attached schema.
+func (m *Model) runInvalidCommand(args string, options map[string]string) (tea.Cmd, error) {
+	if m.table.Validator() == nil {
+		return nil, fmt.Errorf("no schema attached (use --schema)")
+	}
+
+	var filter string
+	if m.table.IsCurrentFilterInvalidOnly() {
+		filter = m.table.GetOriginalFilter()
+	} else {
+		if m.table.InvalidCount() == 0 {
+			return nil, fmt.Errorf("all entries are valid")
+		}
+		filter = m.table.GenerateInvalidOnlyFilter()
+	}
+	return func() tea.Msg {
+		return messages.FilterQueryChanged{Query: filter}
+	}, nil
+}
+
+func renderViolations(violations []schema.Violation) string {
+	lines := make([]string, 0, len(violations)+1)
+	lines = append(lines, styles.NoLabel.Render(fmt.Sprintf("✗ %d schema violations", len(violations))))
+	for _, v := range violations {
+		path := v.Path
+		if path == "" {
+			path = "/"
+		}
+		lines = append(lines, styles.NoLabel.Render("  "+path)+styles.Text.Render(" "+v.Message))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"cutl/internal/dataset"
+	"cutl/internal/editor"
+	"cutl/internal/messages"
+	"cutl/internal/tui/styles"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// previewContext is the number of unchanged lines around every change in
+// the write preview diff.
+const previewContext = 3
+
+// previewWriteCmd compares the rows with the file on disk in the background;
+// the confirmation is asked once the preview is ready.
+func (m *Model) previewWriteCmd() tea.Cmd {
+	m.loading = true
+	m.loadingText = "Comparing with the file on disk..."
+
+	entries := m.withoutVirtualFields(m.table.Entries())
+	origins := m.table.Origins()
+	path := m.jsonlPath
+	options := m.loadOptions
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		original, _, err := editor.Load(path, options)
+		if err != nil {
+			return messages.WritePreviewReady{Error: err}
+		}
+		// JSONL rows are compared with the lines on disk, so a write that
+		// only re-encodes them shows up too.
+		var raw []string
+		if editor.FormatOf(path) == editor.FormatJSONL {
+			if raw, err = editor.ReadLines(path); err != nil {
+				return messages.WritePreviewReady{Error: err}
+			}
+		}
+		return messages.WritePreviewReady{Preview: dataset.PreviewWrite(original, raw, entries, origins, previewContext)}
+	})
+}
+
+func (m *Model) handleWritePreviewReady(msg messages.WritePreviewReady) {
+	m.loading = false
+	m.pendingWriteCmd = m.writeTableToFileCmd()
+	m.confirmationActive = true
+
+	filename := filepath.Base(m.jsonlPath)
+	if msg.Error != nil {
+		m.writePreview = nil
+		m.setStatusMessage(fmt.Sprintf("Write changes to %s? Could not compare: %v (y/N)", filename, msg.Error), false)
+		return
+	}
+
+	preview := msg.Preview
+	m.writePreview = &preview
+	if !preview.Changed() {
+		note := ""
+		if preview.Normalized {
+			note = " (formatting is normalized on write)"
+		}
+		m.setStatusMessage(fmt.Sprintf("No changes to %s%s, write anyway? (y/N)", filename, note), false)
+		return
+	}
+	m.setStatusMessage(fmt.Sprintf("Write to %s: %s? (y/N, D diff)", filename, describePreview(preview)), false)
+}
+
+// describePreview renders the summary, e.g. "rows 2 deleted, 3 modified
+// (5 fields)".
+func describePreview(preview dataset.WritePreview) string {
+	var parts []string
+	if preview.Deleted > 0 {
+		parts = append(parts, fmt.Sprintf("%d deleted", preview.Deleted))
+	}
+	if preview.Modified > 0 {
+		parts = append(parts, fmt.Sprintf("%d modified (%d fields)", preview.Modified, preview.FieldsChanged))
+	}
+	if preview.Added > 0 {
+		parts = append(parts, fmt.Sprintf("%d added", preview.Added))
+	}
+	if preview.Reformatted > 0 {
+		parts = append(parts, fmt.Sprintf("%d reformatted", preview.Reformatted))
+	}
+	text := "rows " + strings.Join(parts, ", ")
+	if len(parts) == 0 {
+		text = "no row changes"
+	}
+	if preview.Dropped > 0 {
+		text += fmt.Sprintf(", %d blank or unreadable lines dropped", preview.Dropped)
+	}
+	if preview.Normalized {
+		text += ", formatting normalized"
+	}
+	return text
+}
+
+// handleWritePreviewKey toggles and scrolls the diff while the write is
+// being confirmed. It reports whether the key was used.
+func (m *Model) handleWritePreviewKey(msg tea.KeyMsg) bool {
+	if m.writePreview == nil || !m.writePreview.Changed() {
+		return false
+	}
+	switch msg.String() {
+	case "d", "D":
+		if m.state == writeDiffView {
+			m.state = m.writeReturnState
+			return true
+		}
+		m.writeReturnState = m.state
+		m.state = writeDiffView
+		m.writeDiffViewport.GotoTop()
+		return true
+	case "up", "down", "k", "j", "pgup", "pgdown", "home", "end", "g", "G":
+		if m.state != writeDiffView {
+			return false
+		}
+		switch msg.String() {
+		case "home", "g":
+			m.writeDiffViewport.GotoTop()
+		case "end", "G":
+			m.writeDiffViewport.GotoBottom()
+		default:
+			m.writeDiffViewport, _ = m.writeDiffViewport.Update(msg)
+		}
+		return true
+	}
+	return false
+}
+
+// closeWritePreview leaves the diff once the write is confirmed or cancelled.
+func (m *Model) closeWritePreview() {
+	if m.state == writeDiffView {
+		m.state = m.writeReturnState
+	}
+	m.writePreview = nil
+}
+
+func (m *Model) renderWritePreviewLines() string {
+	width := m.writeDiffViewport.Width
+	if width < 10 {
+		width = 10
+	}
+	lines := make([]string, 0, len(m.writePreview.Unified)+1)
+	for _, line := range m.writePreview.Unified {
+		text := truncateValue(line, width)
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			lines = append(lines, styles.Label.Render(text))
+		case strings.HasPrefix(line, "-"):
+			lines = append(lines, styles.NoLabel.Render(text))
+		case strings.HasPrefix(line, "+"):
+			lines = append(lines, styles.OkLabel.Render(text))
+		default:
+			lines = append(lines, styles.Text.Render(text))
+		}
+	}
+	if m.writePreview.Truncated {
+		lines = append(lines, styles.InfoLabel.Render("… diff cut off"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *Model) renderWriteDiffView() string {
+	detailStyle := styles.DetailPanel
+	innerWidth := m.width - 8
+	if innerWidth > 0 {
+		detailStyle = detailStyle.Copy().Width(innerWidth)
+	} else {
+		detailStyle = detailStyle.Copy()
+	}
+
+	// Lines are cut to the viewport, which is only sized while rendering.
+	m.writeDiffViewport.SetContent(m.renderWritePreviewLines())
+	info := styles.InfoLabel.Render(fmt.Sprintf(
+		"Pending changes to %s: %s — Y write, N cancel, D hide diff, ↑/↓ scroll",
+		filepath.Base(m.jsonlPath), describePreview(*m.writePreview),
+	))
+	return detailStyle.Render(lipgloss.JoinVertical(lipgloss.Left, info, "", m.writeDiffViewport.View()))
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"cutl/internal"
+	"cutl/internal/dataset"
+	"cutl/internal/editor"
 	"cutl/internal/tui"
 	"cutl/internal/version"
 
@@ -21,6 +24,7 @@ var cmd = &cobra.Command{
 	Version: version.GetVersion(),
 	Short:   "A cozy tool to sift through and modify JSONL files.",
 	Long:    `The main use case is to quickly view and edit large JSONL files in the terminal. The main use-case in mind was the need to manage datasets for machine learning tasks.`,
+	Args:    cobra.ArbitraryArgs,
 
 	Run: func(cmd *cobra.Command, args []string) {
 		var debug, _ = cmd.Flags().GetBool("debug")
@@ -30,33 +34,110 @@ var cmd = &cobra.Command{
 			defer loggerFile.Close()
 		}
 
-		if inputPath == "" {
-			fmt.Println("Please provide a path to a JSONL file using --input.")
-			os.Exit(1)
+		var schemaPath, _ = cmd.Flags().GetString("schema")
+		// Further files given as arguments are opened together as one table.
+		inputs := args
+		if inputPath != "" {
+			inputs = append([]string{inputPath}, args...)
+		}
+		if len(inputs) > 0 {
+			inputPath = inputs[0]
+		}
+		for _, path := range inputs {
+			requireInputFile(path)
 		}
+		requireInputFile(inputPath)
 
-		// Check if the input file exists and is not a directory
-		fileInfo, err := os.Stat(inputPath)
-		if os.IsNotExist(err) {
-			fmt.Printf("Error: File '%s' does not exist.\n", inputPath)
-			os.Exit(1)
-		} else if err != nil {
-			fmt.Printf("Error: Cannot access file '%s': %v\n", inputPath, err)
-			os.Exit(1)
-		} else if fileInfo.IsDir() {
-			fmt.Printf("Error: '%s' is a directory, not a file.\n", inputPath)
-			os.Exit(1)
+		var ui *tui.Model = tui.New(inputPath, schemaPath)
+		ui.SetLoadOptions(loadOptions(cmd))
+		if auditLog, _ := cmd.Flags().GetBool("audit-log"); auditLog {
+			if err := ui.EnableAuditLog(); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if len(inputs) > 1 {
+			var key, _ = cmd.Flags().GetString("key")
+			var onConflict, _ = cmd.Flags().GetString("on-conflict")
+			policy, err := dataset.ParseConflictPolicy(onConflict)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			ui.SetMergeInputs(inputs, dataset.MergeOptions{Key: key, Policy: policy})
 		}
+		runProgram(ui)
+	},
+}
 
-		var ui *tui.Model = tui.New(inputPath)
-		p := tea.NewProgram(ui, tea.WithAltScreen())
-		internal.InitMessageRelay(p.Send)
+// runProgram runs the app until it quits and saves the session afterwards.
+func runProgram(ui *tui.Model) {
+	p := tea.NewProgram(ui, tea.WithAltScreen())
+	internal.InitMessageRelay(p.Send)
 
-		if _, err := p.Run(); err != nil {
-			fmt.Println("Error running program:", err)
+	if _, err := p.Run(); err != nil {
+		fmt.Println("Error running program:", err)
+		os.Exit(1)
+	}
+
+	if err := ui.SaveSession(); err != nil {
+		log.Warnf("Failed to save session: %v", err)
+	}
+	if err := ui.FinishRecording(); err != nil {
+		fmt.Printf("Error: Cannot save the recorded script: %v\n", err)
+	}
+}
+
+func requireInputFile(inputPath string) {
+	if inputPath == "" {
+		fmt.Println("Please provide a path to a JSONL file using --input.")
+		os.Exit(1)
+	}
+
+	// Check if the input file exists and is not a directory
+	fileInfo, err := os.Stat(inputPath)
+	if os.IsNotExist(err) {
+		fmt.Printf("Error: File '%s' does not exist.\n", inputPath)
+		os.Exit(1)
+	} else if err != nil {
+		fmt.Printf("Error: Cannot access file '%s': %v\n", inputPath, err)
+		os.Exit(1)
+	} else if fileInfo.IsDir() {
+		fmt.Printf("Error: '%s' is a directory, not a file.\n", inputPath)
+		os.Exit(1)
+	}
+}
+
+// requireDistinctOutput stops when --output would overwrite one of the
+// inputs, which are only read after the output is created.
+func requireDistinctOutput(outputPath string, inputPaths ...string) {
+	if outputPath == "" {
+		return
+	}
+	for _, inputPath := range inputPaths {
+		if samePath(outputPath, inputPath) {
+			fmt.Printf("Error: Output '%s' is the input file, write to another path.\n", outputPath)
 			os.Exit(1)
 		}
-	},
+	}
+}
+
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA == nil && errB == nil && absA == absB {
+		return true
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// loadOptions reads the flags controlling how CSV and TSV cells are typed.
+func loadOptions(cmd *cobra.Command) editor.LoadOptions {
+	var strings, _ = cmd.Flags().GetBool("strings")
+	var emptyNull, _ = cmd.Flags().GetBool("empty-null")
+	return editor.LoadOptions{Strings: strings, EmptyAsNull: emptyNull}
 }
 
 func initDebugLog(debug bool) *os.File {
@@ -86,6 +167,19 @@ func initDebugLog(debug bool) *os.File {
 func main() {
 	cmd.PersistentFlags().Bool("debug", false, "passing this flag will allow writing debug output to debug.log")
 	cmd.PersistentFlags().String("input", "", "Pfad zu einer JSONL-Datei, die beim Start geladen wird")
+	cmd.PersistentFlags().String("schema", "", "path to a JSON Schema the entries are validated against (remembered per file)")
+	cmd.PersistentFlags().Bool("strings", false, "keep CSV/TSV cells as strings instead of inferring numbers, booleans and JSON")
+	cmd.PersistentFlags().Bool("empty-null", false, "read empty CSV/TSV cells as null instead of empty strings")
+	cmd.Flags().String("key", "", "when opening several files: jq key to deduplicate rows by, e.g. .id")
+	cmd.Flags().Bool("audit-log", false, "append every written delete and edit to a .cutl-log.jsonl file next to the data (remembered per file)")
+	cmd.Flags().String("on-conflict", "interactive", "when opening several files: which version wins for a shared key (first, last or interactive)")
+	cmd.AddCommand(validateCmd)
+	cmd.AddCommand(exportCmd)
+	cmd.AddCommand(splitCmd)
+	cmd.AddCommand(sampleCmd)
+	cmd.AddCommand(mergeCmd)
+	cmd.AddCommand(diffCmd)
+	cmd.AddCommand(applyCmd)
 	
 	// Custom version template to show full version info
 	cmd.SetVersionTemplate(fmt.Sprintf("%s\n", version.GetFullVersion()))
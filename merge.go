@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"cutl/internal/dataset"
+	"cutl/internal/editor"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge FILE...",
+	Short: "Concatenate JSONL files, optionally deduplicating by key.",
+	Long:  `Concatenates the given files (and --input, if set) in order. With --key, entries sharing a jq key such as .id are deduplicated: exact copies are dropped, differing versions are resolved by --on-conflict (first, last or interactive, which asks for every conflict). --provenance records the source file and line of every entry in a _source field; inputs that already have one are refused then. The result is written to --output or as JSONL to stdout.`,
+	Args:  cobra.ArbitraryArgs,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		var inputPath, _ = cmd.Flags().GetString("input")
+		var key, _ = cmd.Flags().GetString("key")
+		var onConflict, _ = cmd.Flags().GetString("on-conflict")
+		var provenance, _ = cmd.Flags().GetBool("provenance")
+		var outputPath, _ = cmd.Flags().GetString("output")
+
+		paths := args
+		if inputPath != "" {
+			paths = append([]string{inputPath}, args...)
+		}
+		if len(paths) < 2 {
+			fmt.Println("Please provide at least two files to merge.")
+			os.Exit(1)
+		}
+		for _, path := range paths {
+			requireInputFile(path)
+		}
+		policy, err := dataset.ParseConflictPolicy(onConflict)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		inputs, header, err := dataset.LoadMergeInputs(paths, loadOptions(cmd))
+		if err != nil {
+			fmt.Printf("Error: Cannot read %v\n", err)
+			os.Exit(1)
+		}
+		result, err := dataset.Merge(inputs, dataset.MergeOptions{Key: key, Policy: policy, Provenance: provenance})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries := result.Entries
+		if len(result.Conflicts) > 0 {
+			if entries, err = resolveConflicts(entries, result.Conflicts); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if outputPath == "" {
+			if err := editor.WriteJSONLTo(os.Stdout, entries); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if err := editor.Write(outputPath, entries, header); err != nil {
+			fmt.Printf("Error: Cannot write '%s': %v\n", outputPath, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Merged %d files into %s: %d entries, %d duplicates dropped.\n", len(paths), outputPath, len(entries), result.Dropped+len(result.Entries)-len(entries))
+	},
+}
+
+// resolveConflicts asks on stderr which version of every conflicting key to
+// keep. Answering "a" keeps all versions.
+func resolveConflicts(entries []editor.Entry, conflicts []dataset.Conflict) ([]editor.Entry, error) {
+	reader := bufio.NewReader(os.Stdin)
+	drop := make(map[int]struct{})
+	for i, conflict := range conflicts {
+		fmt.Fprintf(os.Stderr, "\nConflict %d of %d, key %s:\n", i+1, len(conflicts), conflict.Key)
+		for v, version := range conflict.Versions {
+			source := conflict.Sources[v]
+			data, _ := json.Marshal(version.Data)
+			fmt.Fprintf(os.Stderr, "  [%d] %s:%d  %s\n", v+1, source.File, source.Line, data)
+		}
+
+		for {
+			fmt.Fprintf(os.Stderr, "Keep which version? [1-%d, a = all] ", len(conflict.Versions))
+			answer, err := reader.ReadString('\n')
+			answer = strings.TrimSpace(answer)
+			if answer == "" && err != nil {
+				return nil, fmt.Errorf("no answer for conflict %s: %w", conflict.Key, err)
+			}
+			if answer == "a" {
+				break
+			}
+			choice, convErr := strconv.Atoi(answer)
+			if convErr != nil || choice < 1 || choice > len(conflict.Versions) {
+				if err != nil {
+					return nil, fmt.Errorf("no valid answer for conflict %s", conflict.Key)
+				}
+				continue
+			}
+			for v, version := range conflict.Versions {
+				if v != choice-1 {
+					drop[version.Line] = struct{}{}
+				}
+			}
+			break
+		}
+	}
+
+	kept := make([]editor.Entry, 0, len(entries)-len(drop))
+	for _, entry := range entries {
+		if _, ok := drop[entry.Line]; !ok {
+			kept = append(kept, entry)
+		}
+	}
+	return kept, nil
+}
+
+func init() {
+	mergeCmd.Flags().String("key", "", "jq key to deduplicate by, e.g. .id")
+	mergeCmd.Flags().String("on-conflict", "first", "which version wins when entries share a key: first, last or interactive")
+	mergeCmd.Flags().Bool("provenance", false, "record the source file and line in a _source field")
+	mergeCmd.Flags().StringP("output", "o", "", "file to write instead of stdout (format by extension)")
+}
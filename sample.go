@@ -0,0 +1,69 @@
+package main
+
+import (
+	"cutl/internal/dataset"
+	"cutl/internal/editor"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var sampleCmd = &cobra.Command{
+	Use:   "sample",
+	Short: "Draw a random sample from a JSONL file.",
+	Long:  `Draws -n random entries of --input (optionally narrowed by --filter) with reservoir sampling, or -n entries per group with --per, e.g. --per .label. The sample keeps the original order and is written to --output or as JSONL to stdout. --seed makes it reproducible.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		var inputPath, _ = cmd.Flags().GetString("input")
+		var size, _ = cmd.Flags().GetInt("n")
+		var per, _ = cmd.Flags().GetString("per")
+		var seed, _ = cmd.Flags().GetInt64("seed")
+		var filter, _ = cmd.Flags().GetString("filter")
+		var outputPath, _ = cmd.Flags().GetString("output")
+		requireInputFile(inputPath)
+		requireDistinctOutput(outputPath, inputPath)
+
+		if !cmd.Flags().Changed("seed") {
+			seed = time.Now().UnixNano()
+		}
+
+		entries, header, err := editor.Load(inputPath, loadOptions(cmd))
+		if err != nil {
+			fmt.Printf("Error: Cannot read '%s': %v\n", inputPath, err)
+			os.Exit(1)
+		}
+		if entries, err = filterEntries(entries, filter); err != nil {
+			fmt.Printf("Error: Invalid filter: %v\n", err)
+			os.Exit(1)
+		}
+
+		sample, err := dataset.Sample(entries, dataset.SampleOptions{Size: size, PerGroup: per, Seed: seed})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if outputPath == "" {
+			if err := editor.WriteJSONLTo(os.Stdout, sample); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if err := editor.Write(outputPath, sample, header); err != nil {
+			fmt.Printf("Error: Cannot write '%s': %v\n", outputPath, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Sampled %d of %d entries into %s with seed %d.\n", len(sample), len(entries), outputPath, seed)
+	},
+}
+
+func init() {
+	sampleCmd.Flags().IntP("n", "n", 100, "sample size, or size per group with --per")
+	sampleCmd.Flags().String("per", "", "jq key to sample per group, e.g. .label")
+	sampleCmd.Flags().Int64("seed", 0, "random seed for a reproducible sample (default: random)")
+	sampleCmd.Flags().String("filter", "", "jq filter selecting the entries to sample from")
+	sampleCmd.Flags().StringP("output", "o", "", "file to write instead of stdout (format by extension)")
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"cutl/internal/dataset"
+	"cutl/internal/editor"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split a JSONL file into train/dev/test files.",
+	Long:  `Partitions the entries of --input (optionally narrowed by --filter) into one file per part, e.g. data.train.jsonl, data.dev.jsonl and data.test.jsonl. --stratify keeps the distribution of a jq value equal across parts, --group keeps entries sharing a jq key in one part to avoid leakage, and --seed makes the split reproducible.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		var inputPath, _ = cmd.Flags().GetString("input")
+		var ratios, _ = cmd.Flags().GetString("ratios")
+		var stratify, _ = cmd.Flags().GetString("stratify")
+		var group, _ = cmd.Flags().GetString("group")
+		var seed, _ = cmd.Flags().GetInt64("seed")
+		var filter, _ = cmd.Flags().GetString("filter")
+		var outDir, _ = cmd.Flags().GetString("out-dir")
+		requireInputFile(inputPath)
+
+		parts, err := dataset.ParseSplitParts(ratios)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !cmd.Flags().Changed("seed") {
+			seed = time.Now().UnixNano()
+		}
+
+		entries, header, err := editor.Load(inputPath, loadOptions(cmd))
+		if err != nil {
+			fmt.Printf("Error: Cannot read '%s': %v\n", inputPath, err)
+			os.Exit(1)
+		}
+		if entries, err = filterEntries(entries, filter); err != nil {
+			fmt.Printf("Error: Invalid filter: %v\n", err)
+			os.Exit(1)
+		}
+
+		result, err := dataset.Split(entries, dataset.SplitOptions{
+			Parts:    parts,
+			Stratify: stratify,
+			Group:    group,
+			Seed:     seed,
+		})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		base := inputPath
+		if outDir != "" {
+			base = filepath.Join(outDir, filepath.Base(inputPath))
+		}
+		for i, part := range parts {
+			path := dataset.SplitPath(base, part.Name)
+			if err := editor.Write(path, result[i], header); err != nil {
+				fmt.Printf("Error: Cannot write '%s': %v\n", path, err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s: %d entries -> %s\n", part.Name, len(result[i]), path)
+		}
+		fmt.Printf("Split %d entries with seed %d.\n", len(entries), seed)
+	},
+}
+
+func init() {
+	splitCmd.Flags().String("ratios", "train=0.8,dev=0.1,test=0.1", "parts and their ratios, e.g. train=0.8,test=0.2 or 80,10,10")
+	splitCmd.Flags().String("stratify", "", "jq expression whose distribution is kept equal across parts, e.g. .label")
+	splitCmd.Flags().String("group", "", "jq key keeping related entries in one part, e.g. .doc_id")
+	splitCmd.Flags().Int64("seed", 0, "random seed for a reproducible split (default: random, printed)")
+	splitCmd.Flags().String("filter", "", "jq filter selecting the entries to split")
+	splitCmd.Flags().String("out-dir", "", "directory for the part files (default: next to the input)")
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"cutl/internal/config"
+	"cutl/internal/editor"
+	"cutl/internal/schema"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate a JSONL file against a JSON Schema.",
+	Long:  `Validates every entry of --input against --schema (or the schema attached to the file in the cutl config) and lists all violations. Exits with a non-zero code if any entry is invalid, which makes it usable in CI.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		var inputPath, _ = cmd.Flags().GetString("input")
+		var schemaPath, _ = cmd.Flags().GetString("schema")
+		requireInputFile(inputPath)
+
+		if schemaPath == "" {
+			if cfg, err := config.Load(); err == nil {
+				if fileConfig, exists := cfg.GetFileConfig(inputPath); exists {
+					schemaPath = fileConfig.Schema
+				}
+			}
+		}
+		if schemaPath == "" {
+			fmt.Println("Please provide a JSON Schema using --schema.")
+			os.Exit(1)
+		}
+
+		validator, err := schema.Load(schemaPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		entries, _, err := editor.Load(inputPath, loadOptions(cmd))
+		if err != nil {
+			fmt.Printf("Error: Cannot read '%s': %v\n", inputPath, err)
+			os.Exit(1)
+		}
+
+		violations := validator.ValidateEntries(entries)
+		lines := make([]int, 0, len(violations))
+		for line := range violations {
+			lines = append(lines, line)
+		}
+		sort.Ints(lines)
+
+		for _, line := range lines {
+			for _, v := range violations[line] {
+				fmt.Printf("%s:%d: %s\n", inputPath, line, v)
+			}
+		}
+
+		if len(violations) > 0 {
+			fmt.Printf("%d of %d entries are invalid.\n", len(violations), len(entries))
+			os.Exit(1)
+		}
+		fmt.Printf("All %d entries are valid.\n", len(entries))
+	},
+}